@@ -0,0 +1,6 @@
+package constant
+
+const (
+	CashIn  = "IN"
+	CashOut = "OUT"
+)
@@ -0,0 +1,7 @@
+package constant
+
+const (
+	CostFIFO    = "FIFO"
+	CostAverage = "AVERAGE"
+	CostLatest  = "LATEST"
+)
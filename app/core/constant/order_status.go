@@ -0,0 +1,17 @@
+package constant
+
+const (
+	OPEN               = "OPEN"
+	PENDING            = "PENDING"
+	PAID               = "PAID"
+	VOIDED             = "VOIDED"
+	REFUNDED           = "REFUNDED"
+	PARTIALLY_REFUNDED = "PARTIALLY_REFUNDED"
+	CLOSED             = "CLOSED"
+	APPROVED           = "APPROVED"
+	CANCELLED          = "CANCELLED"
+	DRAFT              = "DRAFT"
+	ORDERED            = "ORDERED"
+	PARTIALLY_RECEIVED = "PARTIALLY_RECEIVED"
+	RECEIVED           = "RECEIVED"
+)
@@ -0,0 +1,6 @@
+package constant
+
+const (
+	OversellReject = "REJECT"
+	OversellAllow  = "ALLOW"
+)
@@ -0,0 +1,8 @@
+package constant
+
+const (
+	CASH      = "CASH"
+	PROMPTPAY = "PROMPTPAY"
+	SPLIT     = "SPLIT"
+	POINTS    = "POINTS"
+)
@@ -0,0 +1,9 @@
+package constant
+
+const (
+	PointEarn    = "EARN"
+	PointRedeem  = "REDEEM"
+	PointReverse = "REVERSE"
+	PointReturn  = "RETURN"
+	PointAdjust  = "ADJUST"
+)
@@ -0,0 +1,8 @@
+package constant
+
+const (
+	PromotionPercent  = "PERCENT"
+	PromotionFixed    = "FIXED"
+	PromotionBuyXGetY = "BUY_X_GET_Y"
+	PromotionCategory = "CATEGORY"
+)
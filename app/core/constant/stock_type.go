@@ -0,0 +1,18 @@
+package constant
+
+const (
+	StockReceive  = "RECEIVE"
+	StockSale     = "SALE"
+	StockVoid     = "VOID"
+	StockReturn   = "RETURN"
+	StockAdjust   = "ADJUST"
+	StockTransfer = "TRANSFER"
+)
+
+const (
+	RefProduct   = "PRODUCT"
+	RefLot       = "LOT"
+	RefOrder     = "ORDER"
+	RefStocktake = "STOCKTAKE"
+	RefPurchase  = "PURCHASE_ORDER"
+)
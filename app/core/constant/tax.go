@@ -0,0 +1,12 @@
+package constant
+
+const (
+	TaxVat    = "VAT"
+	TaxZero   = "ZERO"
+	TaxExempt = "EXEMPT"
+)
+
+const (
+	VatInclusive = "INCLUSIVE"
+	VatExclusive = "EXCLUSIVE"
+)
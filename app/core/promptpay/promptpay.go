@@ -0,0 +1,103 @@
+package promptpay
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"regexp"
+	"strings"
+)
+
+const (
+	aid            = "A000000677010111"
+	tagMobile      = "01"
+	tagNationalId  = "02"
+	tagEWallet     = "03"
+	currencyThb    = "764"
+	countryTh      = "TH"
+	staticPayload  = "11"
+	dynamicPayload = "12"
+)
+
+var nonDigit = regexp.MustCompile(`\D`)
+
+func field(tag string, value string) string {
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
+}
+
+// account formats the PromptPay id, mobile numbers become 0066 and the number without its leading 0
+func account(id string) (string, error) {
+	id = nonDigit.ReplaceAllString(id, "")
+	switch len(id) {
+	case 10:
+		return field(tagMobile, "0066"+id[1:]), nil
+	case 13:
+		return field(tagNationalId, id), nil
+	case 15:
+		return field(tagEWallet, id), nil
+	}
+	return "", errors.New("promptpay id must be a mobile number, tax id or e-wallet id")
+}
+
+// crc16 is CRC-16/CCITT-FALSE as required by EMVCo
+func crc16(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
+
+// Payload builds the EMVCo QR payload for id, a static QR when amount is zero and a dynamic QR with the amount otherwise
+func Payload(id string, amount float64) (string, error) {
+	merchant, err := account(id)
+	if err != nil {
+		return "", err
+	}
+	method := staticPayload
+	if amount > 0 {
+		method = dynamicPayload
+	}
+	var builder strings.Builder
+	builder.WriteString(field("00", "01"))
+	builder.WriteString(field("01", method))
+	builder.WriteString(field("29", field("00", aid)+merchant))
+	builder.WriteString(field("53", currencyThb))
+	if amount > 0 {
+		builder.WriteString(field("54", fmt.Sprintf("%.2f", amount)))
+	}
+	builder.WriteString(field("58", countryTh))
+	builder.WriteString("6304")
+	payload := builder.String()
+	return payload + crc16(payload), nil
+}
+
+// PNG renders the payload as a QR code image of size pixels
+func PNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+// Sign is the hex HMAC-SHA256 of body a webhook sender puts in its signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the webhook signature in constant time
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(strings.ToLower(signature)))
+}
@@ -0,0 +1,80 @@
+package promptpay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrc16(t *testing.T) {
+	// the CRC-16/CCITT-FALSE check value
+	if result := crc16("123456789"); result != "29B1" {
+		t.Errorf("crc16 = %s, want 29B1", result)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		amount   float64
+		expected string
+	}{
+		{
+			name:     "static mobile",
+			id:       "081-234-5678",
+			expected: "00020101021129370016A0000006770101110113006681234567853037645802TH6304823E",
+		},
+		{
+			name:     "dynamic mobile",
+			id:       "0812345678",
+			amount:   150.5,
+			expected: "00020101021229370016A0000006770101110113006681234567853037645406150.505802TH6304CAAD",
+		},
+		{
+			name:     "static tax id",
+			id:       "1234567890123",
+			expected: "00020101021129370016A0000006770101110213123456789012353037645802TH630433FC",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Payload(test.id, test.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.expected {
+				t.Errorf("Payload = %s, want %s", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestPayloadInvalidId(t *testing.T) {
+	if _, err := Payload("12345", 0); err == nil {
+		t.Error("Payload accepted a 5 digit id")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"paymentId":"6401a2b3c4d5e6f708192a3b","amount":107}`)
+	signature := Sign("secret", body)
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		expected  bool
+	}{
+		{name: "valid", secret: "secret", signature: signature, expected: true},
+		{name: "valid upper case", secret: "secret", signature: strings.ToUpper(signature), expected: true},
+		{name: "wrong secret", secret: "other", signature: signature, expected: false},
+		{name: "empty secret", secret: "", signature: Sign("", body), expected: false},
+		{name: "empty signature", secret: "secret", signature: "", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := VerifySignature(test.secret, body, test.signature); result != test.expected {
+				t.Errorf("VerifySignature = %v, want %v", result, test.expected)
+			}
+		})
+	}
+}
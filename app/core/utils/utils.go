@@ -5,7 +5,10 @@ import (
 	"crypto/rand"
 	"devper/app/core/notify"
 	"errors"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -34,6 +37,24 @@ func ToFormat(date time.Time) string {
 	return date.In(location).Format(format)
 }
 
+func RoundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
+
+// GetBranchCode is the BRANCH_CODE of this store, it keys the counters and goes into the document numbers
+func GetBranchCode() string {
+	return os.Getenv("BRANCH_CODE")
+}
+
+// GetVatRate is the VAT percentage from VAT_RATE, 7 when unset
+func GetVatRate() float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("VAT_RATE"), 64)
+	if err != nil {
+		return 7
+	}
+	return rate
+}
+
 const otpChars = "1234567890"
 
 func GenerateCode(length int) string {
@@ -63,3 +84,18 @@ func GenerateRefId(length int) string {
 	}
 	return string(buffer)
 }
+
+// NormalizePhone keeps the digits of a phone number, a +66 country code becomes the leading 0
+func NormalizePhone(phone string) string {
+	digits := make([]byte, 0, len(phone))
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits = append(digits, phone[i])
+		}
+	}
+	value := string(digits)
+	if strings.HasPrefix(phone, "+66") || (len(value) == 11 && strings.HasPrefix(value, "66")) {
+		value = "0" + strings.TrimPrefix(value, "66")
+	}
+	return value
+}
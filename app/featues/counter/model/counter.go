@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type Counter struct {
+	Id          string    `bson:"_id" json:"id"`
+	Seq         int64     `bson:"seq" json:"seq"`
+	UpdatedDate time.Time `bson:"updatedDate" json:"updatedDate"`
+}
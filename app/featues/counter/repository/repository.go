@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"devper/app/featues/counter/model"
+	"devper/db"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type counterEntity struct {
+	counterRepo *mongo.Collection
+}
+
+type ICounter interface {
+	NextSequence(ctx context.Context, key string) (int64, error)
+}
+
+func NewCounterEntity(resource *db.Resource) ICounter {
+	counterRepo := resource.DB.Collection("counters")
+	var entity ICounter = &counterEntity{counterRepo: counterRepo}
+	return entity
+}
+
+// NextSequence atomically increments the counter for key, starting at 1, inside a transaction the
+// number is only taken when it commits
+func (entity *counterEntity) NextSequence(ctx context.Context, key string) (int64, error) {
+	logrus.Info("NextSequence")
+	update := bson.M{
+		"$inc": bson.M{"seq": 1},
+		"$set": bson.M{"updatedDate": time.Now()},
+	}
+	isReturnNewDoc := options.After
+	isUpsert := true
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+		Upsert:         &isUpsert,
+	}
+	var data model.Counter
+	err := entity.counterRepo.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&data)
+	if err != nil {
+		return 0, err
+	}
+	return data.Seq, nil
+}
@@ -0,0 +1,61 @@
+package customer
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/customer/repository"
+	"devper/app/featues/customer/usecase"
+	repository3 "devper/app/featues/order/repository"
+	repository2 "devper/app/featues/user/repository"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplyCustomerAPI(
+	app *gin.RouterGroup,
+	customerEntity repository.ICustomer,
+	orderEntity repository3.IOrder,
+	userEntity repository2.IUser,
+) {
+	customerRoute := app.Group("customer")
+
+	customerRoute.GET("",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetCustomers(customerEntity),
+	)
+
+	customerRoute.POST("",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.CreateCustomer(customerEntity),
+	)
+
+	customerRoute.GET("/phone/:phone",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetCustomerByPhone(customerEntity),
+	)
+
+	customerRoute.GET("/:customerId",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetCustomerById(customerEntity),
+	)
+
+	customerRoute.PUT("/:customerId",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.UpdateCustomerById(customerEntity),
+	)
+
+	customerRoute.DELETE("/:customerId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.DeleteCustomerById(customerEntity),
+	)
+
+	customerRoute.GET("/:customerId/order",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetCustomerOrders(customerEntity, orderEntity),
+	)
+
+	customerRoute.GET("/:customerId/history",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetCustomerHistory(customerEntity, orderEntity),
+	)
+}
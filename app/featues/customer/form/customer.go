@@ -0,0 +1,17 @@
+package form
+
+type Customer struct {
+	Name      string `json:"name" binding:"required"`
+	Phone     string `json:"phone" binding:"required"`
+	Address   string `json:"address"`
+	TaxId     string `json:"taxId" binding:"omitempty,numeric,len=13"`
+	Branch    string `json:"branch" binding:"omitempty,numeric,len=5"`
+	Note      string `json:"note"`
+	UpdatedBy string
+}
+
+type GetCustomers struct {
+	Search string `form:"search"`
+	Page   int64  `form:"page"`
+	Size   int64  `form:"size"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Customer struct {
+	Id          primitive.ObjectID `bson:"_id" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Phone       string             `bson:"phone" json:"phone"`
+	Address     string             `bson:"address" json:"address"`
+	TaxId       string             `bson:"taxId" json:"taxId"`
+	Branch      string             `bson:"branch" json:"branch"`
+	Note        string             `bson:"note" json:"note"`
+	Points      int                `bson:"points" json:"points"`
+	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
+	UpdatedBy   string             `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate time.Time          `bson:"updatedDate" json:"updatedDate"`
+}
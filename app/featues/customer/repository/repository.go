@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/customer/form"
+	"devper/app/featues/customer/model"
+	"devper/db"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrPhoneInvalid = errors.New("phone is invalid")
+
+type customerEntity struct {
+	customerRepo *mongo.Collection
+}
+
+type ICustomer interface {
+	CreateIndex() (string, error)
+	GetCustomers(form form.GetCustomers) ([]model.Customer, int64, error)
+	CreateCustomer(form form.Customer) (*model.Customer, error)
+	GetCustomerById(id string) (*model.Customer, error)
+	GetCustomerByPhone(phone string) (*model.Customer, error)
+	UpdateCustomerById(id string, form form.Customer) (*model.Customer, error)
+	RemoveCustomerById(id string) (*model.Customer, error)
+}
+
+func NewCustomerEntity(resource *db.Resource) ICustomer {
+	customerRepo := resource.DB.Collection("customers")
+	var entity ICustomer = &customerEntity{customerRepo: customerRepo}
+	_, _ = entity.CreateIndex()
+	return entity
+}
+
+func (entity *customerEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mod := mongo.IndexModel{
+		Keys: bson.M{
+			"phone": 1,
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	ind, err := entity.customerRepo.Indexes().CreateOne(ctx, mod)
+	return ind, err
+}
+
+// GetCustomers pages through the customers by name, a search matches part of the name or phone
+func (entity *customerEntity) GetCustomers(form form.GetCustomers) ([]model.Customer, int64, error) {
+	logrus.Info("GetCustomers")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+
+	filter := bson.M{}
+	search := strings.TrimSpace(form.Search)
+	if search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+		or := bson.A{bson.M{"name": pattern}}
+		if phone := utils.NormalizePhone(search); phone != "" {
+			or = append(or, bson.M{"phone": primitive.Regex{Pattern: regexp.QuoteMeta(phone)}})
+		}
+		filter["$or"] = or
+	}
+
+	count, err := entity.customerRepo.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	page := form.Page
+	if page < 1 {
+		page = 1
+	}
+	size := form.Size
+	if size < 1 || size > 100 {
+		size = 20
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip((page - 1) * size).
+		SetLimit(size)
+
+	var items []model.Customer
+	cursor, err := entity.customerRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	for cursor.Next(ctx) {
+		var data model.Customer
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.Customer{}
+	}
+	return items, count, nil
+}
+
+func setCustomer(data *model.Customer, form form.Customer) error {
+	phone := utils.NormalizePhone(form.Phone)
+	if phone == "" {
+		return ErrPhoneInvalid
+	}
+	data.Name = strings.TrimSpace(form.Name)
+	data.Phone = phone
+	data.Address = form.Address
+	data.TaxId = form.TaxId
+	data.Branch = form.Branch
+	data.Note = form.Note
+	data.UpdatedBy = form.UpdatedBy
+	data.UpdatedDate = time.Now()
+	return nil
+}
+
+func (entity *customerEntity) CreateCustomer(form form.Customer) (*model.Customer, error) {
+	logrus.Info("CreateCustomer")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	data := model.Customer{
+		Id:          primitive.NewObjectID(),
+		CreatedBy:   form.UpdatedBy,
+		CreatedDate: time.Now(),
+	}
+	if err := setCustomer(&data, form); err != nil {
+		return nil, err
+	}
+	_, err := entity.customerRepo.InsertOne(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *customerEntity) GetCustomerById(id string) (*model.Customer, error) {
+	logrus.Info("GetCustomerById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Customer
+	err := entity.customerRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *customerEntity) GetCustomerByPhone(phone string) (*model.Customer, error) {
+	logrus.Info("GetCustomerByPhone")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	var data model.Customer
+	err := entity.customerRepo.FindOne(ctx, bson.M{"phone": utils.NormalizePhone(phone)}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// UpdateCustomerById sets the customer details only, the points balance moves through the loyalty ledger
+func (entity *customerEntity) UpdateCustomerById(id string, form form.Customer) (*model.Customer, error) {
+	logrus.Info("UpdateCustomerById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Customer
+	err := entity.customerRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	if err = setCustomer(&data, form); err != nil {
+		return nil, err
+	}
+
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	update := bson.M{"$set": bson.M{
+		"name":        data.Name,
+		"phone":       data.Phone,
+		"address":     data.Address,
+		"taxId":       data.TaxId,
+		"branch":      data.Branch,
+		"note":        data.Note,
+		"updatedBy":   data.UpdatedBy,
+		"updatedDate": data.UpdatedDate,
+	}}
+	err = entity.customerRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *customerEntity) RemoveCustomerById(id string) (*model.Customer, error) {
+	logrus.Info("RemoveCustomerById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	var data model.Customer
+	objId, _ := primitive.ObjectIDFromHex(id)
+	err := entity.customerRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	_, err = entity.customerRepo.DeleteOne(ctx, bson.M{"_id": objId})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
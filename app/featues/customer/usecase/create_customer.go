@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"devper/app/featues/customer/form"
+	"devper/app/featues/customer/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func CreateCustomer(entity repository.ICustomer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.Customer{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		result, err := entity.CreateCustomer(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"devper/app/featues/customer/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetCustomerById(entity repository.ICustomer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		customerId := ctx.Param("customerId")
+		result, err := entity.GetCustomerById(customerId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"devper/app/featues/customer/repository"
+	repository2 "devper/app/featues/order/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetCustomerHistory sums the orders of a customer and the products they bought
+func GetCustomerHistory(customerEntity repository.ICustomer, orderEntity repository2.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		customerId := ctx.Param("customerId")
+		customer, err := customerEntity.GetCustomerById(customerId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := orderEntity.GetPurchaseHistory(customer.Id.Hex())
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"customer": customer,
+			"history":  result,
+		})
+	}
+}
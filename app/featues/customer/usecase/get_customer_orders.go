@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"devper/app/featues/customer/repository"
+	"devper/app/featues/order/form"
+	repository2 "devper/app/featues/order/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// GetCustomerOrders pages through the orders of a customer with the same filters as the order listing
+func GetCustomerOrders(customerEntity repository.ICustomer, orderEntity repository2.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		customerId := ctx.Param("customerId")
+		request := form.GetOrders{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		customer, err := customerEntity.GetCustomerById(customerId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CustomerId = customer.Id.Hex()
+		result, total, err := orderEntity.GetOrders(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"devper/app/featues/customer/form"
+	"devper/app/featues/customer/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func GetCustomers(entity repository.ICustomer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetCustomers{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, total, err := entity.GetCustomers(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
+		ctx.JSON(http.StatusOK, result)
+	}
+}
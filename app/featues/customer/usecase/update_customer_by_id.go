@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"devper/app/featues/customer/form"
+	"devper/app/featues/customer/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func UpdateCustomerById(entity repository.ICustomer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		customerId := ctx.Param("customerId")
+		request := form.Customer{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		result, err := entity.UpdateCustomerById(customerId, request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
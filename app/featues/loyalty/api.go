@@ -0,0 +1,35 @@
+package loyalty
+
+import (
+	"devper/app/core/constant"
+	repository3 "devper/app/featues/customer/repository"
+	"devper/app/featues/loyalty/repository"
+	"devper/app/featues/loyalty/usecase"
+	repository2 "devper/app/featues/user/repository"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplyLoyaltyAPI(
+	app *gin.RouterGroup,
+	pointEntity repository.IPoint,
+	customerEntity repository3.ICustomer,
+	userEntity repository2.IUser,
+) {
+	loyaltyRoute := app.Group("loyalty")
+
+	loyaltyRoute.GET("/rule",
+		usecase.GetRule(),
+	)
+
+	loyaltyRoute.GET("/:customerId/ledger",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetLedger(pointEntity),
+	)
+
+	loyaltyRoute.POST("/:customerId/adjust",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.AdjustPoints(pointEntity, customerEntity),
+	)
+}
@@ -0,0 +1,16 @@
+package form
+
+import "time"
+
+type AdjustPoints struct {
+	Points    int    `json:"points" binding:"required"`
+	Note      string `json:"note" binding:"required"`
+	CreatedBy string
+}
+
+type GetLedger struct {
+	StartDate time.Time `form:"startDate"`
+	EndDate   time.Time `form:"endDate"`
+	Page      int64     `form:"page"`
+	Size      int64     `form:"size"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type PointEntry struct {
+	Id          primitive.ObjectID `bson:"_id" json:"id"`
+	CustomerId  primitive.ObjectID `bson:"customerId" json:"customerId"`
+	OrderId     primitive.ObjectID `bson:"orderId,omitempty" json:"orderId,omitempty"`
+	RefundId    primitive.ObjectID `bson:"refundId,omitempty" json:"refundId,omitempty"`
+	Type        string             `bson:"type" json:"type"`
+	Points      int                `bson:"points" json:"points"`
+	Balance     int                `bson:"balance" json:"balance"`
+	Amount      float64            `bson:"amount" json:"amount"`
+	Note        string             `bson:"note" json:"note"`
+	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
+}
+
+// OrderPoints is what an order moved on the ledger, earned net of reversals and redeemed net of returns
+type OrderPoints struct {
+	Earned   int `bson:"earned" json:"earned"`
+	Redeemed int `bson:"redeemed" json:"redeemed"`
+}
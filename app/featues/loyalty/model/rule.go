@@ -0,0 +1,71 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrPointsInvalid = errors.New("points amount must be a whole number of points")
+
+type Rule struct {
+	BahtPerPoint       float64  `json:"bahtPerPoint"`
+	PointValue         float64  `json:"pointValue"`
+	ExcludedCategories []string `json:"excludedCategories"`
+}
+
+// NewRule reads the loyalty rule from LOYALTY_BAHT_PER_POINT, LOYALTY_POINT_VALUE and
+// LOYALTY_EXCLUDED_CATEGORIES, categories are split on "|"
+func NewRule() Rule {
+	rule := Rule{
+		BahtPerPoint:       getFloat("LOYALTY_BAHT_PER_POINT", 25),
+		PointValue:         getFloat("LOYALTY_POINT_VALUE", 1),
+		ExcludedCategories: []string{},
+	}
+	for _, category := range strings.Split(os.Getenv("LOYALTY_EXCLUDED_CATEGORIES"), "|") {
+		if category = strings.TrimSpace(category); category != "" {
+			rule.ExcludedCategories = append(rule.ExcludedCategories, strings.ToUpper(category))
+		}
+	}
+	return rule
+}
+
+func getFloat(key string, value float64) float64 {
+	parsed, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return value
+	}
+	return parsed
+}
+
+// IsExcluded tells whether sales of the category earn no points
+func (rule Rule) IsExcluded(category string) bool {
+	for _, excluded := range rule.ExcludedCategories {
+		if strings.EqualFold(excluded, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEarnPoints is the whole points an amount earns, none when accrual is turned off
+func (rule Rule) GetEarnPoints(amount float64) int {
+	if rule.BahtPerPoint <= 0 || amount <= 0 {
+		return 0
+	}
+	return int(math.Floor(math.Round(amount*100) / math.Round(rule.BahtPerPoint*100)))
+}
+
+// GetRedeemPoints is the points paying an amount takes
+func (rule Rule) GetRedeemPoints(amount float64) (int, error) {
+	if rule.PointValue <= 0 {
+		return 0, errors.New("points redemption is turned off")
+	}
+	points := math.Round(amount / rule.PointValue)
+	if math.Abs(points*rule.PointValue-amount) > 0.001 {
+		return 0, ErrPointsInvalid
+	}
+	return int(points), nil
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestGetEarnPoints(t *testing.T) {
+	tests := []struct {
+		name         string
+		bahtPerPoint float64
+		amount       float64
+		want         int
+	}{
+		{"whole points", 25, 100, 4},
+		{"rounds down", 25, 99.99, 3},
+		{"nothing to earn on", 25, 0, 0},
+		{"refund amount", 25, -50, 0},
+		{"accrual turned off", 0, 100, 0},
+		{"satang rate without float error", 0.1, 0.3, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := Rule{BahtPerPoint: test.bahtPerPoint}
+			if got := rule.GetEarnPoints(test.amount); got != test.want {
+				t.Errorf("GetEarnPoints(%.2f) = %d, want %d", test.amount, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetRedeemPoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		pointValue float64
+		amount     float64
+		want       int
+		wantErr    bool
+	}{
+		{"baht a point", 1, 30, 30, false},
+		{"half baht a point", 0.5, 10, 20, false},
+		{"satang value without float error", 0.1, 0.3, 3, false},
+		{"part of a point", 2, 25, 0, true},
+		{"redemption turned off", 0, 30, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := Rule{PointValue: test.pointValue}
+			got, err := rule.GetRedeemPoints(test.amount)
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Errorf("GetRedeemPoints(%.2f) = %d, %v, want %d and error %v", test.amount, got, err, test.want, test.wantErr)
+			}
+		})
+	}
+	rule := Rule{PointValue: 2}
+	if _, err := rule.GetRedeemPoints(25); !errors.Is(err, ErrPointsInvalid) {
+		t.Errorf("err = %v, want %v", err, ErrPointsInvalid)
+	}
+}
+
+func TestNewRule(t *testing.T) {
+	_ = os.Setenv("LOYALTY_BAHT_PER_POINT", "20")
+	_ = os.Setenv("LOYALTY_EXCLUDED_CATEGORIES", " tobacco | |Alcohol")
+	defer os.Unsetenv("LOYALTY_BAHT_PER_POINT")
+	defer os.Unsetenv("LOYALTY_EXCLUDED_CATEGORIES")
+	rule := NewRule()
+	want := Rule{BahtPerPoint: 20, PointValue: 1, ExcludedCategories: []string{"TOBACCO", "ALCOHOL"}}
+	if !reflect.DeepEqual(rule, want) {
+		t.Errorf("rule = %+v, want %+v", rule, want)
+	}
+	if !rule.IsExcluded("Tobacco") || rule.IsExcluded("DRINK") {
+		t.Error("IsExcluded should match the excluded categories whatever their case")
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/loyalty/form"
+	"devper/app/featues/loyalty/model"
+	"devper/db"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+var ErrInsufficientPoints = errors.New("insufficient points")
+
+type pointEntity struct {
+	pointRepo    *mongo.Collection
+	customerRepo *mongo.Collection
+}
+
+type IPoint interface {
+	CreateIndex() (string, error)
+	AddPoints(ctx context.Context, entry model.PointEntry) (*model.PointEntry, error)
+	GetOrderPoints(ctx context.Context, orderId primitive.ObjectID) (*model.OrderPoints, error)
+	GetLedger(customerId string, form form.GetLedger) ([]model.PointEntry, int64, error)
+}
+
+func NewPointEntity(resource *db.Resource) IPoint {
+	pointRepo := resource.DB.Collection("point_ledger")
+	customerRepo := resource.DB.Collection("customers")
+	var entity IPoint = &pointEntity{pointRepo: pointRepo, customerRepo: customerRepo}
+	_, _ = entity.CreateIndex()
+	return entity
+}
+
+func (entity *pointEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mods := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "customerId", Value: 1}, {Key: "createdDate", Value: 1}},
+		},
+		{
+			Keys: bson.M{"orderId": 1},
+		},
+	}
+	ind, err := entity.pointRepo.Indexes().CreateMany(ctx, mods)
+	if err != nil {
+		return "", err
+	}
+	return ind[0], nil
+}
+
+// AddPoints moves the customer balance by entry.Points and appends the entry with the resulting balance,
+// a redemption fails with ErrInsufficientPoints instead of going below zero
+func (entity *pointEntity) AddPoints(ctx context.Context, entry model.PointEntry) (*model.PointEntry, error) {
+	logrus.Info("AddPoints")
+	filter := bson.M{"_id": entry.CustomerId}
+	if entry.Type == constant.PointRedeem {
+		filter["points"] = bson.M{"$gte": -entry.Points}
+	}
+	update := bson.M{
+		"$inc": bson.M{"points": entry.Points},
+		"$set": bson.M{"updatedDate": time.Now()},
+	}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+		Projection:     bson.M{"points": 1},
+	}
+	var customer struct {
+		Points int `bson:"points"`
+	}
+	err := entity.customerRepo.FindOneAndUpdate(ctx, filter, update, opts).Decode(&customer)
+	if err == mongo.ErrNoDocuments && entry.Type == constant.PointRedeem {
+		return nil, ErrInsufficientPoints
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Id = primitive.NewObjectID()
+	entry.Balance = customer.Points
+	entry.CreatedDate = time.Now()
+	_, err = entity.pointRepo.InsertOne(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (entity *pointEntity) GetOrderPoints(ctx context.Context, orderId primitive.ObjectID) (*model.OrderPoints, error) {
+	logrus.Info("GetOrderPoints")
+	isType := func(types ...string) bson.M {
+		return bson.M{"$in": bson.A{"$type", types}}
+	}
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"orderId": orderId,
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":      "",
+				"earned":   bson.M{"$sum": bson.M{"$cond": bson.A{isType(constant.PointEarn, constant.PointReverse), "$points", 0}}},
+				"redeemed": bson.M{"$sum": bson.M{"$cond": bson.A{isType(constant.PointRedeem, constant.PointReturn), bson.M{"$multiply": bson.A{"$points", -1}}, 0}}},
+			},
+		},
+	}
+	var result []model.OrderPoints
+	cursor, err := entity.pointRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return &model.OrderPoints{}, nil
+	}
+	return &result[0], nil
+}
+
+func (entity *pointEntity) GetLedger(customerId string, form form.GetLedger) ([]model.PointEntry, int64, error) {
+	logrus.Info("GetLedger")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(customerId)
+	filter := bson.M{"customerId": objId}
+	createdDate := bson.M{}
+	if !form.StartDate.IsZero() {
+		createdDate["$gte"] = form.StartDate
+	}
+	if !form.EndDate.IsZero() {
+		createdDate["$lt"] = form.EndDate
+	}
+	if len(createdDate) > 0 {
+		filter["createdDate"] = createdDate
+	}
+
+	count, err := entity.pointRepo.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	page := form.Page
+	if page < 1 {
+		page = 1
+	}
+	size := form.Size
+	if size < 1 || size > 100 {
+		size = 20
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdDate", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * size).
+		SetLimit(size)
+
+	var items []model.PointEntry
+	cursor, err := entity.pointRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	for cursor.Next(ctx) {
+		var data model.PointEntry
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.PointEntry{}
+	}
+	return items, count, nil
+}
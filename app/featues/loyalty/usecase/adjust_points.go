@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	repository2 "devper/app/featues/customer/repository"
+	"devper/app/featues/loyalty/form"
+	"devper/app/featues/loyalty/model"
+	"devper/app/featues/loyalty/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// AdjustPoints corrects a balance by hand, the note says why on the ledger
+func AdjustPoints(pointEntity repository.IPoint, customerEntity repository2.ICustomer) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		customerId := ctx.Param("customerId")
+		request := form.AdjustPoints{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CreatedBy = ctx.GetString("UserId")
+		customer, err := customerEntity.GetCustomerById(customerId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c, cancel := utils.InitContext()
+		defer cancel()
+		result, err := pointEntity.AddPoints(c, model.PointEntry{
+			CustomerId: customer.Id,
+			Type:       constant.PointAdjust,
+			Points:     request.Points,
+			Note:       request.Note,
+			CreatedBy:  request.CreatedBy,
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
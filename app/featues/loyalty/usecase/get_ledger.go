@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"devper/app/featues/loyalty/form"
+	"devper/app/featues/loyalty/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func GetLedger(entity repository.IPoint) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		customerId := ctx.Param("customerId")
+		request := form.GetLedger{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, total, err := entity.GetLedger(customerId, request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
+		ctx.JSON(http.StatusOK, result)
+	}
+}
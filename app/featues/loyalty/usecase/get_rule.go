@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"devper/app/featues/loyalty/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetRule shows the tills how points are earned and what a point is worth
+func GetRule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, model.NewRule())
+	}
+}
@@ -2,44 +2,138 @@ package order
 
 import (
 	"devper/app/core/constant"
+	repository4 "devper/app/featues/counter/repository"
+	repository6 "devper/app/featues/customer/repository"
+	repository7 "devper/app/featues/loyalty/repository"
 	"devper/app/featues/order/repository"
 	"devper/app/featues/order/usecase"
 	repository2 "devper/app/featues/product/repository"
+	repository5 "devper/app/featues/promotion/repository"
+	repository8 "devper/app/featues/shift/repository"
 	repository3 "devper/app/featues/user/repository"
+	"devper/db"
 	"devper/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
 func ApplyOrderAPI(
 	app *gin.RouterGroup,
+	transaction db.ITransaction,
 	orderEntity repository.IOrder,
 	productEntity repository2.IProduct,
 	userEntity repository3.IUser,
+	counterEntity repository4.ICounter,
+	promotionEntity repository5.IPromotion,
+	customerEntity repository6.ICustomer,
+	pointEntity repository7.IPoint,
+	shiftEntity repository8.IShift,
 ) {
+	usecase.CheckOrderNumberFormat()
+
 	orderRoute := app.Group("order")
 
 	orderRoute.POST("",
-		usecase.CreateOrder(orderEntity, productEntity),
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.CreateOrder(transaction, orderEntity, productEntity, counterEntity, promotionEntity, customerEntity, pointEntity, shiftEntity),
 	)
 
 	orderRoute.GET("",
-		usecase.GetOrdersRange(orderEntity),
+		usecase.GetOrders(orderEntity),
 	)
 
 	orderRoute.GET("/:orderId",
 		usecase.GetOrderById(orderEntity),
 	)
 
+	orderRoute.GET("/promptpay",
+		usecase.GetPromptPay(),
+	)
+
+	orderRoute.POST("/promptpay/webhook",
+		usecase.PromptPayWebhook(orderEntity),
+	)
+
+	orderRoute.GET("/number/:orderNumber",
+		usecase.GetOrderByNumber(orderEntity),
+	)
+
+	orderRoute.GET("/vat-report",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetVatReport(orderEntity),
+	)
+
+	orderRoute.POST("/:orderId/tax-invoice",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.CreateTaxInvoice(transaction, orderEntity, counterEntity),
+	)
+
+	orderRoute.GET("/:orderId/tax-invoice",
+		usecase.GetTaxInvoiceByOrderId(orderEntity),
+	)
+
+	orderRoute.GET("/:orderId/promptpay",
+		usecase.GetOrderPromptPay(orderEntity),
+	)
+
+	orderRoute.POST("/:orderId/payment/:paymentId/confirm",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.ConfirmPaymentById(orderEntity),
+	)
+
+	orderRoute.GET("/:orderId/receipt",
+		usecase.GetOrderReceipt(orderEntity),
+	)
+
 	orderRoute.DELETE("/:orderId",
 		middlewares.RequireAuthenticated(userEntity),
 		middlewares.RequireAuthorization(constant.ADMIN),
-		usecase.DeleteOrderById(orderEntity, productEntity),
+		usecase.VoidOrderById(transaction, orderEntity, productEntity, pointEntity, shiftEntity),
+	)
+
+	orderRoute.POST("/:orderId/void",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.VoidOrderById(transaction, orderEntity, productEntity, pointEntity, shiftEntity),
+	)
+
+	orderRoute.POST("/:orderId/refund",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.RefundOrderById(transaction, orderEntity, productEntity, pointEntity, shiftEntity),
+	)
+
+	orderRoute.POST("/:orderId/item",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.AddOrderItem(transaction, orderEntity, productEntity),
+	)
+
+	orderRoute.PUT("/:orderId/item/:itemId",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.UpdateOrderItemById(transaction, orderEntity, productEntity),
+	)
+
+	orderRoute.DELETE("/:orderId/item/:itemId",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.DeleteOrderItemById(transaction, orderEntity, productEntity),
+	)
+
+	orderRoute.POST("/:orderId/pay",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.PayOrderById(transaction, orderEntity, promotionEntity, customerEntity, pointEntity, shiftEntity),
+	)
+
+	orderRoute.PUT("/:orderId/total-cost",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.UpdateTotalCostById(transaction, orderEntity, productEntity),
 	)
 
 	orderRoute.GET("/:orderId/total-cost",
 		middlewares.RequireAuthenticated(userEntity),
 		middlewares.RequireAuthorization(constant.ADMIN),
-		usecase.UpdateTotalCostById(orderEntity, productEntity),
+		usecase.GetTotalCostById(orderEntity, productEntity),
 	)
 
 	orderRoute.GET("/item",
@@ -53,7 +147,7 @@ func ApplyOrderAPI(
 	orderRoute.DELETE("/item/:itemId",
 		middlewares.RequireAuthenticated(userEntity),
 		middlewares.RequireAuthorization(constant.ADMIN),
-		usecase.DeleteOrderItemById(orderEntity, productEntity),
+		usecase.RefundOrderItemById(transaction, orderEntity, productEntity, pointEntity, shiftEntity),
 	)
 
 	orderRoute.GET("/product/:productId",
@@ -65,7 +159,7 @@ func ApplyOrderAPI(
 	orderRoute.DELETE("/:orderId/product/:productId",
 		middlewares.RequireAuthenticated(userEntity),
 		middlewares.RequireAuthorization(constant.ADMIN),
-		usecase.DeleteOrderItemByOrderProductId(orderEntity, productEntity),
+		usecase.RefundOrderItemByOrderProductId(transaction, orderEntity, productEntity, pointEntity, shiftEntity),
 	)
 
 }
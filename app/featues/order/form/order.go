@@ -1,18 +1,85 @@
 package form
 
-import "time"
+import (
+	"devper/app/featues/promotion/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
 type Order struct {
-	Items     []OrderItem `json:"items" binding:"required"`
-	Amount    float64     `json:"amount" binding:"required"`
-	Type      string      `json:"type" binding:"required"`
-	Total     float64     `json:"total"`
-	TotalCost float64     `json:"totalCost"`
-	Change    float64     `json:"change"`
-	Message   string      `json:"message"`
+	Items       []OrderItem `json:"items" binding:"required"`
+	Amount      float64     `json:"amount"`
+	Type        string      `json:"type"`
+	Total       float64     `json:"total"`
+	TotalCost   float64     `json:"totalCost"`
+	Change      float64     `json:"change"`
+	Message     string      `json:"message"`
+	Hold        bool        `json:"hold"`
+	Payments    []Payment   `json:"payments" binding:"omitempty,dive"`
+	Coupon      string      `json:"coupon"`
+	CustomerId  string      `json:"customerId"`
+	MemberPhone string      `json:"memberPhone"`
+
+	Id              primitive.ObjectID       `json:"-"`
+	OrderNumber     string                   `json:"-"`
+	Branch          string                   `json:"-"`
+	VatMode         string                   `json:"-"`
+	VatableAmount   float64                  `json:"-"`
+	ZeroRatedAmount float64                  `json:"-"`
+	ExemptAmount    float64                  `json:"-"`
+	VatAmount       float64                  `json:"-"`
+	GrossTotal      float64                  `json:"-"`
+	Discount        float64                  `json:"-"`
+	Promotions      []model.AppliedPromotion `json:"-"`
+	PointsEarned    int                      `json:"-"`
+	PointsRedeemed  int                      `json:"-"`
+	ShiftId         string                   `json:"-"`
+	CreatedBy       string
+}
+
+type PayOrder struct {
+	Amount      float64   `json:"amount"`
+	Type        string    `json:"type"`
+	Change      float64   `json:"change"`
+	Payments    []Payment `json:"payments" binding:"omitempty,dive"`
+	Coupon      string    `json:"coupon"`
+	CustomerId  string    `json:"customerId"`
+	MemberPhone string    `json:"memberPhone"`
+
+	Promotions     []model.AppliedPromotion `json:"-"`
+	PointsEarned   int                      `json:"-"`
+	PointsRedeemed int                      `json:"-"`
+	ShiftId        string                   `json:"-"`
+	UpdatedBy      string
+}
+
+type UpdateOrderItem struct {
+	Quantity int     `json:"quantity" binding:"required"`
+	Discount float64 `json:"discount"`
+	Price    float64 `json:"price"`
+}
+
+type GetOrders struct {
+	StartDate  time.Time `form:"startDate"`
+	EndDate    time.Time `form:"endDate"`
+	Status     string    `form:"status"`
+	Type       string    `form:"type"`
+	CreatedBy  string    `form:"createdBy"`
+	MinTotal   *float64  `form:"minTotal"`
+	MaxTotal   *float64  `form:"maxTotal"`
+	ProductId  string    `form:"productId"`
+	CustomerId string    `form:"customerId"`
+	ShiftId    string    `form:"shiftId"`
+	Sort       string    `form:"sort"`
+	Page       int64     `form:"page"`
+	Size       int64     `form:"size"`
 }
 
 type GetOrderRange struct {
 	StartDate time.Time `form:"startDate" binding:"required"`
 	EndDate   time.Time `form:"endDate" binding:"required"`
 }
+
+type UpdateTotalCost struct {
+	Method string `form:"method" json:"method" binding:"omitempty,oneof=FIFO AVERAGE LATEST"`
+}
@@ -1,9 +1,20 @@
 package form
 
+import "devper/app/featues/product/model"
+
 type OrderItem struct {
-	ProductId string  `json:"productId" binding:"required"`
-	Quantity  int     `json:"quantity" binding:"required"`
-	Price     float64 `json:"price" binding:"required"`
-	CostPrice float64 `json:"costPrice"`
-	Discount  float64 `json:"discount"`
+	ProductId string                `json:"productId" binding:"required"`
+	Quantity  int                   `json:"quantity" binding:"required"`
+	Price     float64               `json:"price"`
+	CostPrice float64               `json:"costPrice"`
+	Discount  float64               `json:"discount"`
+	Lots      []model.LotAllocation `json:"-"`
+	TaxClass  string                `json:"-"`
+	VatRate   float64               `json:"-"`
+	VatAmount float64               `json:"-"`
+	Category  string                `json:"-"`
+	Gross     float64               `json:"-"`
+
+	PromotionDiscount float64 `json:"-"`
+	UpdatedBy         string
 }
@@ -0,0 +1,24 @@
+package form
+
+type Payment struct {
+	Type      string  `json:"type" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required"`
+	Reference string  `json:"reference"`
+	Change    float64 `json:"-"`
+}
+
+type ConfirmPayment struct {
+	Reference string `json:"reference"`
+	UpdatedBy string
+}
+
+type PromptPayWebhook struct {
+	PaymentId string  `json:"paymentId" binding:"required"`
+	Reference string  `json:"reference" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required"`
+}
+
+type GetPromptPay struct {
+	Format string `form:"format" binding:"omitempty,oneof=text png"`
+	Size   int    `form:"size" binding:"omitempty,min=128,max=1024"`
+}
@@ -0,0 +1,8 @@
+package form
+
+type GetReceipt struct {
+	Format string `form:"format" binding:"omitempty,oneof=text html pdf escpos"`
+	Width  int    `form:"width" binding:"omitempty,oneof=80 58"`
+	Lang   string `form:"lang" binding:"omitempty,oneof=th en"`
+	Code   string `form:"code" binding:"omitempty,oneof=barcode qr"`
+}
@@ -0,0 +1,19 @@
+package form
+
+type Refund struct {
+	Items     []RefundItem `json:"items" binding:"required,dive"`
+	Reason    string       `json:"reason" binding:"required"`
+	Type      string       `json:"type"`
+	ShiftId   string       `json:"-"`
+	CreatedBy string
+}
+
+type RefundItem struct {
+	ItemId   string `json:"itemId" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required"`
+}
+
+type VoidOrder struct {
+	Reason string `form:"reason" json:"reason"`
+	Type   string `form:"type" json:"type"`
+}
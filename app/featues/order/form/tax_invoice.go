@@ -0,0 +1,16 @@
+package form
+
+import "time"
+
+type TaxInvoice struct {
+	BuyerName    string `json:"buyerName" binding:"required"`
+	BuyerTaxId   string `json:"buyerTaxId" binding:"required,numeric,len=13"`
+	BuyerBranch  string `json:"buyerBranch" binding:"omitempty,numeric,len=5"`
+	BuyerAddress string `json:"buyerAddress" binding:"required"`
+	CreatedBy    string
+}
+
+type GetVatReport struct {
+	StartDate time.Time `form:"startDate" binding:"required"`
+	EndDate   time.Time `form:"endDate" binding:"required"`
+}
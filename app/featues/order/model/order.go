@@ -1,32 +1,63 @@
 package model
 
 import (
+	"devper/app/featues/promotion/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
 )
 
 type Order struct {
-	Id          primitive.ObjectID `bson:"_id" json:"id"`
-	Status      string             `bson:"status" json:"status"`
-	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
-	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
-	UpdatedBy   string             `bson:"updatedBy" json:"updatedBy"`
-	UpdatedDate time.Time          `bson:"updatedDate" json:"updatedDate"`
-	Total       float64            `bson:"total" json:"total"`
-	TotalCost   float64            `bson:"totalCost" json:"totalCost"`
-	Type        string             `bson:"type" json:"type"`
+	Id              primitive.ObjectID       `bson:"_id" json:"id"`
+	OrderNumber     string                   `bson:"orderNumber,omitempty" json:"orderNumber"`
+	Branch          string                   `bson:"branch" json:"branch"`
+	Status          string                   `bson:"status" json:"status"`
+	CustomerId      primitive.ObjectID       `bson:"customerId,omitempty" json:"customerId,omitempty"`
+	ShiftId         primitive.ObjectID       `bson:"shiftId,omitempty" json:"shiftId,omitempty"`
+	CreatedBy       string                   `bson:"createdBy" json:"createdBy"`
+	CreatedDate     time.Time                `bson:"createdDate" json:"createdDate"`
+	UpdatedBy       string                   `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate     time.Time                `bson:"updatedDate" json:"updatedDate"`
+	Total           float64                  `bson:"total" json:"total"`
+	TotalCost       float64                  `bson:"totalCost" json:"totalCost"`
+	Type            string                   `bson:"type" json:"type"`
+	VatMode         string                   `bson:"vatMode" json:"vatMode"`
+	VatableAmount   float64                  `bson:"vatableAmount" json:"vatableAmount"`
+	ZeroRatedAmount float64                  `bson:"zeroRatedAmount" json:"zeroRatedAmount"`
+	ExemptAmount    float64                  `bson:"exemptAmount" json:"exemptAmount"`
+	VatAmount       float64                  `bson:"vatAmount" json:"vatAmount"`
+	GrossTotal      float64                  `bson:"grossTotal" json:"grossTotal"`
+	Discount        float64                  `bson:"discount" json:"discount"`
+	Promotions      []model.AppliedPromotion `bson:"promotions" json:"promotions"`
+	PointsEarned    int                      `bson:"pointsEarned" json:"pointsEarned"`
+	PointsRedeemed  int                      `bson:"pointsRedeemed" json:"pointsRedeemed"`
 }
 
 type OrderDetail struct {
-	Id          primitive.ObjectID `bson:"_id" json:"id"`
-	Status      string             `bson:"status" json:"status"`
-	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
-	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
-	UpdatedBy   string             `bson:"updatedBy" json:"updatedBy"`
-	UpdatedDate time.Time          `bson:"updatedDate" json:"updatedDate"`
-	Total       float64            `bson:"total" json:"total"`
-	TotalCost   float64            `bson:"totalCost" json:"totalCost"`
-	Type        string             `bson:"type" json:"type"`
-	Items       []OrderItemDetail  `json:"items"`
-	Payment     Payment            `json:"payment"`
+	Id              primitive.ObjectID       `bson:"_id" json:"id"`
+	OrderNumber     string                   `bson:"orderNumber,omitempty" json:"orderNumber"`
+	Branch          string                   `bson:"branch" json:"branch"`
+	Status          string                   `bson:"status" json:"status"`
+	CustomerId      primitive.ObjectID       `bson:"customerId,omitempty" json:"customerId,omitempty"`
+	ShiftId         primitive.ObjectID       `bson:"shiftId,omitempty" json:"shiftId,omitempty"`
+	CreatedBy       string                   `bson:"createdBy" json:"createdBy"`
+	CreatedDate     time.Time                `bson:"createdDate" json:"createdDate"`
+	UpdatedBy       string                   `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate     time.Time                `bson:"updatedDate" json:"updatedDate"`
+	Total           float64                  `bson:"total" json:"total"`
+	TotalCost       float64                  `bson:"totalCost" json:"totalCost"`
+	Type            string                   `bson:"type" json:"type"`
+	VatMode         string                   `bson:"vatMode" json:"vatMode"`
+	VatableAmount   float64                  `bson:"vatableAmount" json:"vatableAmount"`
+	ZeroRatedAmount float64                  `bson:"zeroRatedAmount" json:"zeroRatedAmount"`
+	ExemptAmount    float64                  `bson:"exemptAmount" json:"exemptAmount"`
+	VatAmount       float64                  `bson:"vatAmount" json:"vatAmount"`
+	GrossTotal      float64                  `bson:"grossTotal" json:"grossTotal"`
+	Discount        float64                  `bson:"discount" json:"discount"`
+	Promotions      []model.AppliedPromotion `bson:"promotions" json:"promotions"`
+	PointsEarned    int                      `bson:"pointsEarned" json:"pointsEarned"`
+	PointsRedeemed  int                      `bson:"pointsRedeemed" json:"pointsRedeemed"`
+	Items           []OrderItemDetail        `json:"items"`
+	Payments        []Payment                `json:"payments"`
+	Refunds         []Refund                 `json:"refunds"`
+	TaxInvoice      *TaxInvoice              `json:"taxInvoice"`
 }
@@ -8,34 +8,83 @@ import (
 )
 
 type OrderItem struct {
-	Id          primitive.ObjectID `bson:"_id" json:"id"`
-	OrderId     primitive.ObjectID `bson:"orderId" json:"orderId"`
-	ProductId   primitive.ObjectID `bson:"productId" json:"productId"`
-	Quantity    int                `bson:"quantity" json:"quantity"`
-	Price       float64            `bson:"price" json:"price"`
-	CostPrice   float64            `bson:"costPrice" json:"costPrice"`
-	Discount    float64            `bson:"discount" json:"discount"`
-	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
-	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
-	UpdatedBy   string             `bson:"updatedBy" json:"updatedBy"`
-	UpdatedDate time.Time          `bson:"updatedDate" json:"updatedDate"`
+	Id                primitive.ObjectID    `bson:"_id" json:"id"`
+	OrderId           primitive.ObjectID    `bson:"orderId" json:"orderId"`
+	ProductId         primitive.ObjectID    `bson:"productId" json:"productId"`
+	Quantity          int                   `bson:"quantity" json:"quantity"`
+	Price             float64               `bson:"price" json:"price"`
+	CostPrice         float64               `bson:"costPrice" json:"costPrice"`
+	Discount          float64               `bson:"discount" json:"discount"`
+	TaxClass          string                `bson:"taxClass" json:"taxClass"`
+	VatRate           float64               `bson:"vatRate" json:"vatRate"`
+	VatAmount         float64               `bson:"vatAmount" json:"vatAmount"`
+	PromotionDiscount float64               `bson:"promotionDiscount" json:"promotionDiscount"`
+	Lots              []model.LotAllocation `bson:"lots" json:"lots"`
+	RefundedQuantity  int                   `bson:"refundedQuantity" json:"refundedQuantity"`
+	CreatedBy         string                `bson:"createdBy" json:"createdBy"`
+	CreatedDate       time.Time             `bson:"createdDate" json:"createdDate"`
+	UpdatedBy         string                `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate       time.Time             `bson:"updatedDate" json:"updatedDate"`
 }
 
 type OrderItemDetail struct {
-	Id          primitive.ObjectID `bson:"_id" json:"id"`
-	OrderId     primitive.ObjectID `bson:"orderId" json:"orderId"`
-	ProductId   primitive.ObjectID `bson:"productId" json:"productId"`
-	Quantity    int                `bson:"quantity" json:"quantity"`
-	Price       float64            `bson:"price" json:"price"`
-	CostPrice   float64            `bson:"costPrice" json:"costPrice"`
-	Discount    float64            `bson:"discount" json:"discount"`
-	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
-	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
-	UpdatedBy   string             `bson:"updatedBy" json:"updatedBy"`
-	UpdatedDate time.Time          `bson:"updatedDate" json:"updatedDate"`
-	Product     model.Product      `bson:"product" json:"product"`
+	Id                primitive.ObjectID    `bson:"_id" json:"id"`
+	OrderId           primitive.ObjectID    `bson:"orderId" json:"orderId"`
+	ProductId         primitive.ObjectID    `bson:"productId" json:"productId"`
+	Quantity          int                   `bson:"quantity" json:"quantity"`
+	Price             float64               `bson:"price" json:"price"`
+	CostPrice         float64               `bson:"costPrice" json:"costPrice"`
+	Discount          float64               `bson:"discount" json:"discount"`
+	TaxClass          string                `bson:"taxClass" json:"taxClass"`
+	VatRate           float64               `bson:"vatRate" json:"vatRate"`
+	VatAmount         float64               `bson:"vatAmount" json:"vatAmount"`
+	PromotionDiscount float64               `bson:"promotionDiscount" json:"promotionDiscount"`
+	Lots              []model.LotAllocation `bson:"lots" json:"lots"`
+	RefundedQuantity  int                   `bson:"refundedQuantity" json:"refundedQuantity"`
+	CreatedBy         string                `bson:"createdBy" json:"createdBy"`
+	CreatedDate       time.Time             `bson:"createdDate" json:"createdDate"`
+	UpdatedBy         string                `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate       time.Time             `bson:"updatedDate" json:"updatedDate"`
+	Product           model.Product         `bson:"product" json:"product"`
 }
 
 func (item OrderItemDetail) GetMessage() string {
 	return fmt.Sprintf("%s จำนวน %d %s ราคา %.2f บาท", item.Product.Name, item.Quantity, item.Product.Unit, item.Price)
 }
+
+// GetRefundLots picks the lots quantity more refunded units go back to, units without a lot are refunded first
+func (item OrderItemDetail) GetRefundLots(quantity int) []model.LotAllocation {
+	unallocated := item.Quantity
+	for _, lot := range item.Lots {
+		unallocated -= lot.Quantity
+	}
+	skip := item.RefundedQuantity
+	if unallocated > 0 {
+		used := unallocated
+		if used > skip {
+			used = skip
+		}
+		skip -= used
+		unallocated -= used
+		if unallocated > quantity {
+			unallocated = quantity
+		}
+		quantity -= unallocated
+	}
+	lots := []model.LotAllocation{}
+	for i := len(item.Lots) - 1; i >= 0 && quantity > 0; i-- {
+		lot := item.Lots[i]
+		if skip >= lot.Quantity {
+			skip -= lot.Quantity
+			continue
+		}
+		lot.Quantity -= skip
+		skip = 0
+		if lot.Quantity > quantity {
+			lot.Quantity = quantity
+		}
+		quantity -= lot.Quantity
+		lots = append(lots, lot)
+	}
+	return lots
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type PurchaseHistory struct {
+	Orders         int               `bson:"orders" json:"orders"`
+	GrossTotal     float64           `bson:"grossTotal" json:"grossTotal"`
+	Discount       float64           `bson:"discount" json:"discount"`
+	Refund         float64           `bson:"refund" json:"refund"`
+	Total          float64           `bson:"total" json:"total"`
+	FirstOrderDate time.Time         `bson:"firstOrderDate" json:"firstOrderDate"`
+	LastOrderDate  time.Time         `bson:"lastOrderDate" json:"lastOrderDate"`
+	Products       []ProductPurchase `bson:"-" json:"products"`
+}
+
+type ProductPurchase struct {
+	ProductId     primitive.ObjectID `bson:"_id" json:"productId"`
+	Name          string             `bson:"name" json:"name"`
+	Unit          string             `bson:"unit" json:"unit"`
+	Quantity      int                `bson:"quantity" json:"quantity"`
+	Total         float64            `bson:"total" json:"total"`
+	LastOrderDate time.Time          `bson:"lastOrderDate" json:"lastOrderDate"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"devper/app/featues/product/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Refund is one void or refund of an order, Type is VOIDED or REFUNDED as it was made, whatever the order became after
+type Refund struct {
+	Id              primitive.ObjectID `bson:"_id" json:"id"`
+	OrderId         primitive.ObjectID `bson:"orderId" json:"orderId"`
+	Type            string             `bson:"type" json:"type"`
+	PaymentId       primitive.ObjectID `bson:"paymentId" json:"paymentId"`
+	Reason          string             `bson:"reason" json:"reason"`
+	Total           float64            `bson:"total" json:"total"`
+	VatableAmount   float64            `bson:"vatableAmount" json:"vatableAmount"`
+	ZeroRatedAmount float64            `bson:"zeroRatedAmount" json:"zeroRatedAmount"`
+	ExemptAmount    float64            `bson:"exemptAmount" json:"exemptAmount"`
+	VatAmount       float64            `bson:"vatAmount" json:"vatAmount"`
+	Items           []RefundItem       `bson:"items" json:"items"`
+	Tenders         []RefundTender     `bson:"tenders" json:"tenders"`
+	ShiftId         primitive.ObjectID `bson:"shiftId,omitempty" json:"shiftId,omitempty"`
+	CreatedBy       string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate     time.Time          `bson:"createdDate" json:"createdDate"`
+}
+
+// RefundTender is the part of a refund paid back through one tender of the order
+type RefundTender struct {
+	PaymentId primitive.ObjectID `bson:"paymentId" json:"paymentId"`
+	Type      string             `bson:"type" json:"type"`
+	Amount    float64            `bson:"amount" json:"amount"`
+}
+
+type RefundItem struct {
+	ItemId    primitive.ObjectID    `bson:"itemId" json:"itemId"`
+	ProductId primitive.ObjectID    `bson:"productId" json:"productId"`
+	Quantity  int                   `bson:"quantity" json:"quantity"`
+	Amount    float64               `bson:"amount" json:"amount"`
+	VatAmount float64               `bson:"vatAmount" json:"vatAmount"`
+	Lots      []model.LotAllocation `bson:"lots" json:"lots"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type TaxInvoice struct {
+	Id              primitive.ObjectID `bson:"_id" json:"id"`
+	OrderId         primitive.ObjectID `bson:"orderId" json:"orderId"`
+	Number          string             `bson:"number" json:"number"`
+	BuyerName       string             `bson:"buyerName" json:"buyerName"`
+	BuyerTaxId      string             `bson:"buyerTaxId" json:"buyerTaxId"`
+	BuyerBranch     string             `bson:"buyerBranch" json:"buyerBranch"`
+	BuyerAddress    string             `bson:"buyerAddress" json:"buyerAddress"`
+	VatableAmount   float64            `bson:"vatableAmount" json:"vatableAmount"`
+	ZeroRatedAmount float64            `bson:"zeroRatedAmount" json:"zeroRatedAmount"`
+	ExemptAmount    float64            `bson:"exemptAmount" json:"exemptAmount"`
+	VatAmount       float64            `bson:"vatAmount" json:"vatAmount"`
+	Total           float64            `bson:"total" json:"total"`
+	CreatedBy       string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate     time.Time          `bson:"createdDate" json:"createdDate"`
+}
+
+type VatSummary struct {
+	Date            string  `bson:"_id" json:"date"`
+	Orders          int     `bson:"orders" json:"orders"`
+	Refunds         int     `bson:"refunds" json:"refunds"`
+	VatableAmount   float64 `bson:"vatableAmount" json:"vatableAmount"`
+	ZeroRatedAmount float64 `bson:"zeroRatedAmount" json:"zeroRatedAmount"`
+	ExemptAmount    float64 `bson:"exemptAmount" json:"exemptAmount"`
+	VatAmount       float64 `bson:"vatAmount" json:"vatAmount"`
+	Total           float64 `bson:"total" json:"total"`
+}
+
+type VatReport struct {
+	Days        []VatSummary `json:"days"`
+	Total       VatSummary   `json:"total"`
+	TaxInvoices []TaxInvoice `json:"taxInvoices"`
+}
@@ -0,0 +1,137 @@
+package receipt
+
+import (
+	"bytes"
+	"devper/app/featues/order/model"
+	"strings"
+)
+
+const (
+	CodeNone    = ""
+	CodeBarcode = "barcode"
+	CodeQr      = "qr"
+)
+
+var (
+	escInit        = []byte{0x1B, 0x40}
+	escAlignLeft   = []byte{0x1B, 0x61, 0x00}
+	escAlignCenter = []byte{0x1B, 0x61, 0x01}
+	escBoldOn      = []byte{0x1B, 0x45, 0x01}
+	escBoldOff     = []byte{0x1B, 0x45, 0x00}
+	escDoubleOn    = []byte{0x1D, 0x21, 0x11}
+	escDoubleOff   = []byte{0x1D, 0x21, 0x00}
+	escFeedCut     = []byte{0x1D, 0x56, 0x42, 0x00}
+)
+
+// encodeTis620 converts text to the single byte Thai encoding thermal printers use
+func encodeTis620(text string) []byte {
+	result := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r < 0x80:
+			result = append(result, byte(r))
+		case r >= 0x0E01 && r <= 0x0E5B:
+			result = append(result, byte(r-0x0E01+0xA1))
+		default:
+			result = append(result, '?')
+		}
+	}
+	return result
+}
+
+type escposWriter struct {
+	bytes.Buffer
+}
+
+func (w *escposWriter) line(text string) {
+	w.Write(encodeTis620(text))
+	w.WriteByte('\n')
+}
+
+func (w *escposWriter) barcode(data string) {
+	// GS h height, GS w module width, GS H text below, GS k CODE128 with code set B
+	w.Write([]byte{0x1D, 0x68, 80, 0x1D, 0x77, 0x01, 0x1D, 0x48, 0x02})
+	payload := append([]byte("{B"), data...)
+	w.Write([]byte{0x1D, 0x6B, 73, byte(len(payload))})
+	w.Write(payload)
+	w.WriteByte('\n')
+}
+
+func (w *escposWriter) qr(data string) {
+	// GS ( k: model 2, module size, error correction M, store then print
+	w.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00})
+	w.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, 0x06})
+	w.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31})
+	size := len(data) + 3
+	w.Write([]byte{0x1D, 0x28, 0x6B, byte(size % 256), byte(size / 256), 0x31, 0x50, 0x30})
+	w.WriteString(data)
+	w.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30})
+	w.WriteByte('\n')
+}
+
+// ESCPOS renders the receipt as a raw printer byte stream, the output depends only on the order and config
+// so it can be compared against golden files without a printer
+func ESCPOS(order *model.OrderDetail, config Config, code string) []byte {
+	doc := newDocument(order, config)
+	width := config.Width
+	separator := strings.Repeat("-", width)
+	w := &escposWriter{}
+
+	w.Write(escInit)
+	w.Write([]byte{0x1B, 0x74, config.CodePage})
+	w.Write(escAlignCenter)
+	for i, header := range doc.Header {
+		if i == 0 {
+			w.Write(escBoldOn)
+			w.line(truncate(header, width))
+			w.Write(escBoldOff)
+			continue
+		}
+		w.line(truncate(header, width))
+	}
+	w.Write(escAlignLeft)
+	w.line(separator)
+	w.Write(escBoldOn)
+	w.line(center(doc.Title, width))
+	w.Write(escBoldOff)
+	for _, info := range doc.Info {
+		w.line(justify(info.Label, info.Value, width))
+	}
+	w.line(separator)
+	for _, item := range doc.Items {
+		for _, name := range wrap(item.Name, width) {
+			w.line(name)
+		}
+		w.line(justify("  "+item.Detail, item.Amount, width))
+		if item.Discount != "" {
+			w.line(justify("  "+doc.DiscountLabel, item.Discount, width))
+		}
+	}
+	w.line(separator)
+	for _, total := range doc.Totals {
+		if total.Bold {
+			// double size halves the columns
+			w.Write(escBoldOn)
+			w.Write(escDoubleOn)
+			w.line(justify(total.Label, total.Value, width/2))
+			w.Write(escDoubleOff)
+			w.Write(escBoldOff)
+			continue
+		}
+		w.line(justify(total.Label, total.Value, width))
+	}
+	w.line(separator)
+	w.Write(escAlignCenter)
+	switch code {
+	case CodeBarcode:
+		w.barcode(order.Id.Hex())
+	case CodeQr:
+		w.qr(order.Id.Hex())
+	}
+	for _, footer := range doc.Footer {
+		w.line(truncate(footer, width))
+	}
+	w.Write(escAlignLeft)
+	w.Write(escFeedCut)
+	return w.Bytes()
+}
@@ -0,0 +1,89 @@
+package receipt
+
+import (
+	"bytes"
+	"devper/app/core/constant"
+	"devper/app/featues/order/model"
+	model2 "devper/app/featues/product/model"
+	"flag"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func getTestOrder() *model.OrderDetail {
+	orderId, _ := primitive.ObjectIDFromHex("6401a2b3c4d5e6f708192a3b")
+	return &model.OrderDetail{
+		Id:            orderId,
+		OrderNumber:   "20260301-0001",
+		Status:        constant.PAID,
+		CreatedDate:   time.Date(2026, 3, 1, 3, 4, 5, 0, time.UTC),
+		Total:         107,
+		VatMode:       constant.VatInclusive,
+		VatableAmount: 100,
+		VatAmount:     7,
+		Items: []model.OrderItemDetail{
+			{
+				Quantity: 2,
+				Price:    80,
+				Discount: 10,
+				Product:  model2.Product{Name: "กาแฟเย็น", NameEn: "Iced coffee", Unit: "แก้ว"},
+			},
+			{
+				Quantity: 1,
+				Price:    27,
+				Product:  model2.Product{Name: "ขนมปัง", NameEn: "Bread", Unit: "ชิ้น"},
+			},
+		},
+		Payments: []model.Payment{
+			{Type: "CASH", Amount: 200, Change: 93},
+		},
+	}
+}
+
+func getTestConfig(lang string) Config {
+	return Config{
+		ShopName: "Devper Shop",
+		Header:   []string{"1 Sukhumvit Rd", "02-000-0000"},
+		Footer:   []string{},
+		TaxId:    "0105500000000",
+		VatRate:  7,
+		Lang:     lang,
+		Width:    Width58mm,
+		CodePage: 26,
+	}
+}
+
+func TestESCPOS(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		code string
+	}{
+		{name: "escpos_th.bin", lang: LangTh, code: CodeNone},
+		{name: "escpos_en_qr.bin", lang: LangEn, code: CodeQr},
+		{name: "escpos_en_barcode.bin", lang: LangEn, code: CodeBarcode},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := ESCPOS(getTestOrder(), getTestConfig(test.lang), test.code)
+			golden := filepath.Join("testdata", test.name)
+			if *update {
+				if err := os.WriteFile(golden, result, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(result, expected) {
+				t.Errorf("output differs from %s, run go test -update to accept it\n%q", golden, result)
+			}
+		})
+	}
+}
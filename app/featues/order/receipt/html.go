@@ -0,0 +1,60 @@
+package receipt
+
+import (
+	"bytes"
+	"devper/app/featues/order/model"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Doc.Title}}</title>
+<style>
+body { width: {{.Paper}}mm; margin: 0 auto; font-family: monospace; font-size: 12px; }
+.center { text-align: center; }
+.row { display: flex; justify-content: space-between; }
+.item { padding-left: 1em; }
+.bold { font-weight: bold; }
+hr { border: none; border-top: 1px dashed #000; }
+</style>
+</head>
+<body>
+{{range .Doc.Header}}<div class="center">{{.}}</div>
+{{end}}<hr>
+<div class="center bold">{{.Doc.Title}}</div>
+{{range .Doc.Info}}<div class="row"><span>{{.Label}}</span><span>{{.Value}}</span></div>
+{{end}}<hr>
+{{range .Doc.Items}}<div>{{.Name}}</div>
+<div class="row item"><span>{{.Detail}}</span><span>{{.Amount}}</span></div>
+{{if .Discount}}<div class="row item"><span>{{$.Doc.DiscountLabel}}</span><span>{{.Discount}}</span></div>
+{{end}}{{end}}<hr>
+{{range .Doc.Totals}}<div class="row{{if .Bold}} bold{{end}}"><span>{{.Label}}</span><span>{{.Value}}</span></div>
+{{end}}<hr>
+{{range .Doc.Footer}}<div class="center">{{.}}</div>
+{{end}}</body>
+</html>
+`))
+
+// getPaperWidth maps the character width of a thermal roll to its paper width in millimetres
+func getPaperWidth(width int) float64 {
+	if width <= Width58mm {
+		return 58
+	}
+	return 80
+}
+
+// HTML renders the receipt as a printable page sized to the thermal roll
+func HTML(order *model.OrderDetail, config Config) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := htmlTemplate.Execute(&buffer, map[string]interface{}{
+		"Lang":  config.Lang,
+		"Paper": getPaperWidth(config.Width),
+		"Doc":   newDocument(order, config),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,109 @@
+package receipt
+
+import (
+	"bytes"
+	"devper/app/featues/order/model"
+	"github.com/go-pdf/fpdf"
+	"os"
+)
+
+const (
+	pdfMargin     = 3.0
+	pdfLineHeight = 4.0
+	pdfFont       = "receipt"
+)
+
+// setPdfFont uses the TrueType font at RECEIPT_FONT_PATH, the core Courier font has no Thai glyphs
+func setPdfFont(pdf *fpdf.Fpdf) (string, func(string) string) {
+	path := os.Getenv("RECEIPT_FONT_PATH")
+	if path == "" {
+		pdf.SetFont("Courier", "", 8)
+		return "Courier", pdf.UnicodeTranslatorFromDescriptor("")
+	}
+	font, err := os.ReadFile(path)
+	if err != nil {
+		pdf.SetError(err)
+		return pdfFont, func(text string) string { return text }
+	}
+	pdf.AddUTF8FontFromBytes(pdfFont, "", font)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", font)
+	pdf.SetFont(pdfFont, "", 8)
+	return pdfFont, func(text string) string { return text }
+}
+
+// PDF renders the receipt as a single page the size of the thermal roll, without RECEIPT_FONT_PATH the labels are
+// in English as Courier cannot draw Thai
+func PDF(order *model.OrderDetail, config Config) ([]byte, error) {
+	if os.Getenv("RECEIPT_FONT_PATH") == "" {
+		config.Lang = LangEn
+	}
+	doc := newDocument(order, config)
+	paper := getPaperWidth(config.Width)
+	height := float64(len(doc.lines(config.Width)))*pdfLineHeight + 2*pdfMargin
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: paper, Ht: height},
+	})
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	family, tr := setPdfFont(pdf)
+	pdf.AddPage()
+
+	width := paper - 2*pdfMargin
+	separator := func() {
+		y := pdf.GetY() + pdfLineHeight/2
+		pdf.SetDashPattern([]float64{1, 1}, 0)
+		pdf.Line(pdfMargin, y, paper-pdfMargin, y)
+		pdf.Ln(pdfLineHeight)
+	}
+	centered := func(text string) {
+		pdf.CellFormat(width, pdfLineHeight, tr(text), "", 1, "C", false, 0, "")
+	}
+	justified := func(indent float64, label string, value string, bold bool) {
+		if bold {
+			pdf.SetFont(family, "B", 0)
+		}
+		valueWidth := pdf.GetStringWidth(tr(value)) + 1
+		pdf.CellFormat(indent, pdfLineHeight, "", "", 0, "L", false, 0, "")
+		pdf.CellFormat(width-indent-valueWidth, pdfLineHeight, tr(label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(valueWidth, pdfLineHeight, tr(value), "", 1, "R", false, 0, "")
+		if bold {
+			pdf.SetFont(family, "", 0)
+		}
+	}
+
+	for _, header := range doc.Header {
+		centered(header)
+	}
+	separator()
+	pdf.SetFont(family, "B", 0)
+	centered(doc.Title)
+	pdf.SetFont(family, "", 0)
+	for _, info := range doc.Info {
+		justified(0, info.Label, info.Value, false)
+	}
+	separator()
+	for _, item := range doc.Items {
+		pdf.MultiCell(width, pdfLineHeight, tr(item.Name), "", "L", false)
+		justified(2, item.Detail, item.Amount, false)
+		if item.Discount != "" {
+			justified(2, doc.DiscountLabel, item.Discount, false)
+		}
+	}
+	separator()
+	for _, total := range doc.Totals {
+		justified(0, total.Label, total.Value, total.Bold)
+	}
+	separator()
+	for _, footer := range doc.Footer {
+		centered(footer)
+	}
+
+	var buffer bytes.Buffer
+	err := pdf.Output(&buffer)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
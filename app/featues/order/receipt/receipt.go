@@ -0,0 +1,288 @@
+package receipt
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/order/model"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	Width80mm = 48
+	Width58mm = 32
+)
+
+const (
+	LangTh = "th"
+	LangEn = "en"
+)
+
+type Config struct {
+	ShopName string
+	Header   []string
+	Footer   []string
+	TaxId    string
+	VatRate  float64
+	Lang     string
+	Width    int
+	CodePage byte
+}
+
+// NewConfig reads the shop details from the RECEIPT_* environment, header and footer lines are split on "|"
+func NewConfig() Config {
+	return Config{
+		ShopName: os.Getenv("RECEIPT_SHOP_NAME"),
+		Header:   splitLines(os.Getenv("RECEIPT_HEADER")),
+		Footer:   splitLines(os.Getenv("RECEIPT_FOOTER")),
+		TaxId:    os.Getenv("RECEIPT_TAX_ID"),
+		VatRate:  utils.GetVatRate(),
+		Lang:     LangTh,
+		Width:    Width80mm,
+		CodePage: getCodePage(),
+	}
+}
+
+// getCodePage is the ESC t table the printer maps TIS-620 to from RECEIPT_ESCPOS_CODE_PAGE, 26 on Epson Thai models
+func getCodePage() byte {
+	codePage, err := strconv.Atoi(os.Getenv("RECEIPT_ESCPOS_CODE_PAGE"))
+	if err != nil || codePage < 0 || codePage > 255 {
+		return 26
+	}
+	return byte(codePage)
+}
+
+func splitLines(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, "|")
+}
+
+type labels struct {
+	Title     string
+	ShortTax  string
+	FullTax   string
+	TaxId     string
+	Buyer     string
+	Branch    string
+	Order     string
+	Date      string
+	Subtotal  string
+	Discount  string
+	Total     string
+	BeforeVat string
+	Vat       string
+	ZeroRated string
+	Exempt    string
+	Change    string
+	Refund    string
+	Voided    string
+	Points    string
+	ThankYou  string
+}
+
+var labelsByLang = map[string]labels{
+	LangTh: {
+		Title:     "ใบเสร็จรับเงิน",
+		ShortTax:  "ใบเสร็จรับเงิน/ใบกำกับภาษีอย่างย่อ",
+		FullTax:   "ใบเสร็จรับเงิน/ใบกำกับภาษี",
+		TaxId:     "เลขประจำตัวผู้เสียภาษี",
+		Buyer:     "ผู้ซื้อ",
+		Branch:    "สาขา",
+		Order:     "เลขที่",
+		Date:      "วันที่",
+		Subtotal:  "รวมเป็นเงิน",
+		Discount:  "ส่วนลด",
+		Total:     "ยอดสุทธิ",
+		BeforeVat: "มูลค่าก่อนภาษี",
+		Vat:       "ภาษีมูลค่าเพิ่ม",
+		ZeroRated: "ภาษีอัตราศูนย์",
+		Exempt:    "ยกเว้นภาษี",
+		Change:    "เงินทอน",
+		Refund:    "คืนเงิน",
+		Voided:    "ยกเลิก",
+		Points:    "แต้มสะสมที่ได้รับ",
+		ThankYou:  "ขอบคุณที่ใช้บริการ",
+	},
+	LangEn: {
+		Title:     "Receipt",
+		ShortTax:  "Receipt/Abbreviated Tax Invoice",
+		FullTax:   "Receipt/Tax Invoice",
+		TaxId:     "Tax ID",
+		Buyer:     "Buyer",
+		Branch:    "Branch",
+		Order:     "No.",
+		Date:      "Date",
+		Subtotal:  "Subtotal",
+		Discount:  "Discount",
+		Total:     "Total",
+		BeforeVat: "Before VAT",
+		Vat:       "VAT",
+		ZeroRated: "Zero-rated",
+		Exempt:    "VAT exempt",
+		Change:    "Change",
+		Refund:    "Refund",
+		Voided:    "Voided",
+		Points:    "Points earned",
+		ThankYou:  "Thank you",
+	},
+}
+
+var paymentTypeByLang = map[string]map[string]string{
+	LangTh: {"CASH": "เงินสด", "TRANSFER": "โอนเงิน", "CARD": "บัตร", "PROMPTPAY": "พร้อมเพย์", "POINTS": "แลกแต้ม"},
+	LangEn: {"CASH": "Cash", "TRANSFER": "Transfer", "CARD": "Card", "PROMPTPAY": "PromptPay", "POINTS": "Points"},
+}
+
+type row struct {
+	Label string
+	Value string
+	Bold  bool
+}
+
+type itemRow struct {
+	Name     string
+	Detail   string
+	Amount   string
+	Discount string
+}
+
+// document is the layout every receipt format renders from
+type document struct {
+	Title         string
+	Header        []string
+	Info          []row
+	Items         []itemRow
+	DiscountLabel string
+	Totals        []row
+	Footer        []string
+}
+
+func getLabels(lang string) labels {
+	if value, ok := labelsByLang[lang]; ok {
+		return value
+	}
+	return labelsByLang[LangTh]
+}
+
+func formatMoney(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
+
+func newDocument(order *model.OrderDetail, config Config) document {
+	label := getLabels(config.Lang)
+	doc := document{
+		Title:         label.Title,
+		Header:        []string{},
+		DiscountLabel: label.Discount,
+		Footer:        config.Footer,
+	}
+	if order.TaxInvoice != nil {
+		doc.Title = label.FullTax
+	} else if config.TaxId != "" {
+		doc.Title = label.ShortTax
+	}
+	if order.Status == constant.VOIDED {
+		doc.Title += " (" + label.Voided + ")"
+	}
+	if config.ShopName != "" {
+		doc.Header = append(doc.Header, config.ShopName)
+	}
+	doc.Header = append(doc.Header, config.Header...)
+	if config.TaxId != "" {
+		doc.Header = append(doc.Header, label.TaxId+" "+config.TaxId)
+	}
+	if len(doc.Footer) == 0 {
+		doc.Footer = []string{label.ThankYou}
+	}
+	orderNumber := order.OrderNumber
+	if orderNumber == "" {
+		orderNumber = order.Id.Hex()
+	}
+	doc.Info = []row{
+		{Label: label.Order, Value: orderNumber},
+		{Label: label.Date, Value: utils.ToFormat(order.CreatedDate)},
+	}
+	if invoice := order.TaxInvoice; invoice != nil {
+		doc.Info = append(doc.Info,
+			row{Label: label.FullTax, Value: invoice.Number},
+			row{Label: label.Buyer, Value: invoice.BuyerName},
+			row{Label: label.TaxId, Value: invoice.BuyerTaxId},
+			row{Label: label.Branch, Value: invoice.BuyerBranch},
+		)
+		for _, address := range strings.Split(invoice.BuyerAddress, "\n") {
+			doc.Info = append(doc.Info, row{Label: address})
+		}
+	}
+
+	subtotal := 0.0
+	discount := 0.0
+	for _, item := range order.Items {
+		name := item.Product.Name
+		if config.Lang == LangEn && item.Product.NameEn != "" {
+			name = item.Product.NameEn
+		}
+		gross := item.Price + item.Discount
+		if order.VatMode == constant.VatExclusive {
+			gross -= item.VatAmount
+		}
+		unitPrice := 0.0
+		if item.Quantity != 0 {
+			unitPrice = gross / float64(item.Quantity)
+		}
+		line := itemRow{
+			Name:   name,
+			Detail: fmt.Sprintf("%d %s x %s", item.Quantity, item.Product.Unit, formatMoney(unitPrice)),
+			Amount: formatMoney(gross),
+		}
+		if item.Discount > 0 {
+			line.Discount = "-" + formatMoney(item.Discount)
+		}
+		doc.Items = append(doc.Items, line)
+		subtotal += gross
+		discount += item.Discount
+	}
+
+	doc.Totals = append(doc.Totals, row{Label: label.Subtotal, Value: formatMoney(subtotal)})
+	if discount > 0 {
+		doc.Totals = append(doc.Totals, row{Label: label.Discount, Value: "-" + formatMoney(discount)})
+	}
+	for _, promotion := range order.Promotions {
+		doc.Totals = append(doc.Totals, row{Label: "  " + promotion.Name, Value: "-" + formatMoney(promotion.Amount)})
+	}
+	vat := row{Label: fmt.Sprintf("%s %g%%", label.Vat, config.VatRate), Value: formatMoney(order.VatAmount)}
+	total := row{Label: label.Total, Value: formatMoney(order.Total), Bold: true}
+	if order.VatMode == constant.VatExclusive {
+		doc.Totals = append(doc.Totals, vat, total)
+	} else {
+		doc.Totals = append(doc.Totals, total, row{Label: label.BeforeVat, Value: formatMoney(order.VatableAmount)}, vat)
+	}
+	if order.ZeroRatedAmount > 0 {
+		doc.Totals = append(doc.Totals, row{Label: label.ZeroRated, Value: formatMoney(order.ZeroRatedAmount)})
+	}
+	if order.ExemptAmount > 0 {
+		doc.Totals = append(doc.Totals, row{Label: label.Exempt, Value: formatMoney(order.ExemptAmount)})
+	}
+	change := 0.0
+	for _, payment := range order.Payments {
+		if payment.Type == "" {
+			continue
+		}
+		paymentType := payment.Type
+		if value, ok := paymentTypeByLang[config.Lang][paymentType]; ok {
+			paymentType = value
+		}
+		doc.Totals = append(doc.Totals, row{Label: paymentType, Value: formatMoney(payment.Amount)})
+		change += payment.Change
+	}
+	doc.Totals = append(doc.Totals, row{Label: label.Change, Value: formatMoney(change)})
+	for _, refund := range order.Refunds {
+		doc.Totals = append(doc.Totals, row{Label: label.Refund, Value: "-" + formatMoney(refund.Total)})
+	}
+	if order.PointsEarned > 0 {
+		doc.Totals = append(doc.Totals, row{Label: label.Points, Value: fmt.Sprintf("%d", order.PointsEarned)})
+	}
+	return doc
+}
@@ -0,0 +1,109 @@
+package receipt
+
+import (
+	"devper/app/featues/order/model"
+	"strings"
+	"unicode/utf8"
+)
+
+// isCombining reports Thai vowels and tone marks that print above or below the previous character
+func isCombining(r rune) bool {
+	return r == 0x0E31 || (r >= 0x0E34 && r <= 0x0E3A) || (r >= 0x0E47 && r <= 0x0E4E)
+}
+
+func displayWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		if !isCombining(r) {
+			width++
+		}
+	}
+	return width
+}
+
+func truncate(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if displayWidth(text) <= width {
+		return text
+	}
+	var builder strings.Builder
+	current := 0
+	for _, r := range text {
+		if !isCombining(r) {
+			if current == width {
+				break
+			}
+			current++
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+func center(text string, width int) string {
+	text = truncate(text, width)
+	return strings.Repeat(" ", (width-displayWidth(text))/2) + text
+}
+
+func justify(left string, right string, width int) string {
+	left = truncate(left, width-displayWidth(right)-1)
+	space := width - displayWidth(left) - displayWidth(right)
+	if space < 1 {
+		space = 1
+	}
+	return left + strings.Repeat(" ", space) + right
+}
+
+// wrap breaks text on display width, keeping combining marks with their base character
+func wrap(text string, width int) []string {
+	var lines []string
+	for displayWidth(text) > width {
+		line := truncate(text, width)
+		lines = append(lines, line)
+		text = text[len(line):]
+		for len(text) > 0 {
+			r, size := utf8.DecodeRuneInString(text)
+			if !isCombining(r) {
+				break
+			}
+			text = text[size:]
+		}
+	}
+	return append(lines, text)
+}
+
+func (doc document) lines(width int) []string {
+	separator := strings.Repeat("-", width)
+	var lines []string
+	for _, header := range doc.Header {
+		lines = append(lines, center(header, width))
+	}
+	lines = append(lines, separator, center(doc.Title, width))
+	for _, info := range doc.Info {
+		lines = append(lines, justify(info.Label, info.Value, width))
+	}
+	lines = append(lines, separator)
+	for _, item := range doc.Items {
+		lines = append(lines, wrap(item.Name, width)...)
+		lines = append(lines, justify("  "+item.Detail, item.Amount, width))
+		if item.Discount != "" {
+			lines = append(lines, justify("  "+doc.DiscountLabel, item.Discount, width))
+		}
+	}
+	lines = append(lines, separator)
+	for _, total := range doc.Totals {
+		lines = append(lines, justify(total.Label, total.Value, width))
+	}
+	lines = append(lines, separator)
+	for _, footer := range doc.Footer {
+		lines = append(lines, center(footer, width))
+	}
+	return lines
+}
+
+// Text renders the receipt for a thermal printer of config.Width characters
+func Text(order *model.OrderDetail, config Config) string {
+	return strings.Join(newDocument(order, config).lines(config.Width), "\n") + "\n"
+}
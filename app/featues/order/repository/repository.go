@@ -1,73 +1,164 @@
 package repository
 
 import (
+	"context"
 	"devper/app/core/constant"
 	"devper/app/core/utils"
 	"devper/app/featues/order/form"
 	"devper/app/featues/order/model"
 	"devper/db"
+	"errors"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"sort"
+	"strings"
 	"time"
 )
 
+var ErrOrderNotPayable = errors.New("order is not open or active")
+
 type orderEntity struct {
-	orderRepo     *mongo.Collection
-	orderItemRepo *mongo.Collection
-	paymentRepo   *mongo.Collection
+	orderRepo      *mongo.Collection
+	orderItemRepo  *mongo.Collection
+	paymentRepo    *mongo.Collection
+	refundRepo     *mongo.Collection
+	taxInvoiceRepo *mongo.Collection
 }
 
 type IOrder interface {
-	CreateOrder(form form.Order) (*model.Order, error)
-	GetOrderRange(form form.GetOrderRange) ([]model.Order, error)
+	CreateIndex() (string, error)
+	CreateOrder(ctx context.Context, form form.Order) (*model.Order, error)
+	GetOrders(form form.GetOrders) ([]model.Order, int64, error)
 	UpdateTotal() ([]model.Order, error)
 	GetOrderById(id string) (*model.Order, error)
-	GetOrderDetailById(id string) (*model.OrderDetail, error)
-	UpdateTotalCostOrderById(id string, totalCost float64) (*model.Order, error)
-	RemoveOrderById(id string) (*model.OrderDetail, error)
-	UpdateTotalOrderById(id string) (*model.Order, error)
-	GetTotalOrderById(id string) float64
-	GetTotalCostOrderById(id string) float64
+	GetOrderDetailById(ctx context.Context, id string) (*model.OrderDetail, error)
+	GetOrderDetailByNumber(branch string, orderNumber string) (*model.OrderDetail, error)
+	UpdateTotalCostOrderById(ctx context.Context, id string, totalCost float64, updatedBy string) (*model.Order, error)
+	UpdateTotalOrderById(ctx context.Context, id string) (*model.Order, error)
+	GetTotalOrderById(ctx context.Context, id string) float64
+	GetTotalCostOrderById(ctx context.Context, id string) float64
+	UpdateStatusById(ctx context.Context, id string, status string, updatedBy string) (*model.Order, error)
 
 	GetOrderItemRange(form form.GetOrderRange) ([]model.OrderItemDetail, error)
-	GetOrderItemById(id string) (*model.OrderItem, error)
-	UpdateOrderItemById(id string, form form.OrderItem) (*model.OrderItem, error)
-	RemoveOrderItemById(id string) (*model.OrderItemDetail, error)
+	GetOrderItemById(ctx context.Context, id string) (*model.OrderItem, error)
+	CreateOrderItem(ctx context.Context, orderId string, form form.OrderItem) (*model.OrderItem, error)
+	UpdateOrderItemById(ctx context.Context, id string, form form.OrderItem) (*model.OrderItem, error)
+	UpdateOrderItemPriceById(ctx context.Context, id string, form form.OrderItem) (*model.OrderItem, error)
+	UpdateOrderItemCostById(ctx context.Context, id string, costPrice float64, updatedBy string) (*model.OrderItem, error)
+	RemoveOrderItemById(ctx context.Context, id string) (*model.OrderItemDetail, error)
 	GetOrderItemDetailById(id string) (*model.OrderItemDetail, error)
 	GetOrderItemDetailByOrderId(orderId string) ([]model.OrderItemDetail, error)
-	GetOrderItemDetailByOrderProductId(orderId string, productId string) (*model.OrderItemDetail, error)
-	RemoveOrderItemByOrderProductId(orderId string, productId string) (*model.OrderItemDetail, error)
 	GetOrderItemByProductId(productId string) ([]model.OrderItem, error)
+	AddRefundedQuantityById(ctx context.Context, id string, quantity int) (*model.OrderItem, error)
+
+	GetPaymentsByOrderId(orderId string) ([]model.Payment, error)
+	GetPaymentById(id string) (*model.Payment, error)
+	ConfirmPaymentById(id string, form form.ConfirmPayment) (*model.Payment, error)
+	PayOrderById(ctx context.Context, id string, form form.PayOrder) (*model.Order, error)
 
-	GetPaymentByOrderId(orderId string) (*model.Payment, error)
-	RemovePaymentByOrderId(orderId string) (*model.Payment, error)
+	CreateRefund(ctx context.Context, refund model.Refund) (*model.Refund, error)
+	GetRefundsByOrderId(orderId string) ([]model.Refund, error)
+
+	CreateTaxInvoice(ctx context.Context, invoice model.TaxInvoice) (*model.TaxInvoice, error)
+	GetTaxInvoiceByOrderId(ctx context.Context, orderId string) (*model.TaxInvoice, error)
+	GetVatReport(form form.GetVatReport) (*model.VatReport, error)
+	GetPurchaseHistory(customerId string) (*model.PurchaseHistory, error)
 }
 
 func NewOrderEntity(resource *db.Resource) IOrder {
 	orderRepo := resource.DB.Collection("orders")
 	orderItemRepo := resource.DB.Collection("order_items")
 	paymentRepo := resource.DB.Collection("payments")
-	var entity IOrder = &orderEntity{orderRepo: orderRepo, orderItemRepo: orderItemRepo, paymentRepo: paymentRepo}
+	refundRepo := resource.DB.Collection("refunds")
+	taxInvoiceRepo := resource.DB.Collection("tax_invoices")
+	var entity IOrder = &orderEntity{orderRepo: orderRepo, orderItemRepo: orderItemRepo, paymentRepo: paymentRepo, refundRepo: refundRepo, taxInvoiceRepo: taxInvoiceRepo}
+	_, _ = entity.CreateIndex()
 	return entity
 }
 
-func (entity *orderEntity) CreateOrder(form form.Order) (*model.Order, error) {
-	logrus.Info("CreateOrder")
+func (entity *orderEntity) CreateIndex() (string, error) {
 	ctx, cancel := utils.InitContext()
 	defer cancel()
+	mods := []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"orderId": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{
+				"number": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	ind, err := entity.taxInvoiceRepo.Indexes().CreateMany(ctx, mods)
+	if err != nil {
+		return "", err
+	}
+	mod := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "branch", Value: 1},
+			{Key: "orderNumber", Value: 1},
+		},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"orderNumber": bson.M{"$type": "string"},
+		}),
+	}
+	orderInd, err := entity.orderRepo.Indexes().CreateOne(ctx, mod)
+	if err != nil {
+		return "", err
+	}
+	// the order list looks up payments and items by order
+	orderIdMod := mongo.IndexModel{Keys: bson.M{"orderId": 1}}
+	if _, err = entity.paymentRepo.Indexes().CreateOne(ctx, orderIdMod); err != nil {
+		return "", err
+	}
+	_, err = entity.orderItemRepo.Indexes().CreateOne(ctx, orderIdMod)
+	return strings.Join(append(ind, orderInd), ","), err
+}
+
+func (entity *orderEntity) CreateOrder(ctx context.Context, form form.Order) (*model.Order, error) {
+	logrus.Info("CreateOrder")
 
-	var orderId = primitive.NewObjectID()
+	var orderId = form.Id
+	if orderId.IsZero() {
+		orderId = primitive.NewObjectID()
+	}
+	customerId, _ := primitive.ObjectIDFromHex(form.CustomerId)
+	shiftId, _ := primitive.ObjectIDFromHex(form.ShiftId)
+	status := constant.ACTIVE
+	if form.Hold {
+		status = constant.OPEN
+	}
 	data := model.Order{
-		Id:          orderId,
-		Status:      constant.ACTIVE,
-		Total:       form.Total,
-		TotalCost:   form.TotalCost,
-		Type:        form.Type,
-		CreatedDate: time.Now(),
-		UpdatedDate: time.Now(),
+		Id:              orderId,
+		OrderNumber:     form.OrderNumber,
+		Branch:          form.Branch,
+		Status:          status,
+		CustomerId:      customerId,
+		ShiftId:         shiftId,
+		Total:           form.Total,
+		TotalCost:       form.TotalCost,
+		Type:            form.Type,
+		VatMode:         form.VatMode,
+		VatableAmount:   form.VatableAmount,
+		ZeroRatedAmount: form.ZeroRatedAmount,
+		ExemptAmount:    form.ExemptAmount,
+		VatAmount:       form.VatAmount,
+		GrossTotal:      form.GrossTotal,
+		Discount:        form.Discount,
+		Promotions:      form.Promotions,
+		PointsEarned:    form.PointsEarned,
+		PointsRedeemed:  form.PointsRedeemed,
+		CreatedBy:       form.CreatedBy,
+		CreatedDate:     time.Now(),
+		UpdatedBy:       form.CreatedBy,
+		UpdatedDate:     time.Now(),
 	}
 	_, err := entity.orderRepo.InsertOne(ctx, data)
 	if err != nil {
@@ -78,17 +169,27 @@ func (entity *orderEntity) CreateOrder(form form.Order) (*model.Order, error) {
 	orderItem := make([]interface{}, count)
 	for i := 0; i < count; i++ {
 		formItem := form.Items[i]
-		productId, _ := primitive.ObjectIDFromHex(formItem.ProductId)
+		productId, err := primitive.ObjectIDFromHex(formItem.ProductId)
+		if err != nil {
+			return nil, err
+		}
 		item := model.OrderItem{
-			Id:          primitive.NewObjectID(),
-			OrderId:     orderId,
-			ProductId:   productId,
-			Quantity:    formItem.Quantity,
-			Price:       formItem.Price,
-			CostPrice:   formItem.CostPrice,
-			Discount:    formItem.Discount,
-			CreatedDate: time.Now(),
-			UpdatedDate: time.Now(),
+			Id:                primitive.NewObjectID(),
+			OrderId:           orderId,
+			ProductId:         productId,
+			Quantity:          formItem.Quantity,
+			Price:             formItem.Price,
+			CostPrice:         formItem.CostPrice,
+			Discount:          formItem.Discount,
+			TaxClass:          formItem.TaxClass,
+			VatRate:           formItem.VatRate,
+			VatAmount:         formItem.VatAmount,
+			PromotionDiscount: formItem.PromotionDiscount,
+			Lots:              formItem.Lots,
+			CreatedBy:         form.CreatedBy,
+			CreatedDate:       time.Now(),
+			UpdatedBy:         form.CreatedBy,
+			UpdatedDate:       time.Now(),
 		}
 		orderItem[i] = item
 	}
@@ -97,52 +198,189 @@ func (entity *orderEntity) CreateOrder(form form.Order) (*model.Order, error) {
 		return nil, err
 	}
 
-	payment := model.Payment{
-		Id:          primitive.NewObjectID(),
-		OrderId:     orderId,
-		Status:      constant.ACTIVE,
-		Amount:      form.Amount,
-		Total:       form.Total,
-		Change:      form.Change,
-		Type:        form.Type,
-		CreatedDate: time.Now(),
-		UpdatedDate: time.Now(),
+	if form.Hold {
+		payment := model.Payment{
+			Id:          primitive.NewObjectID(),
+			OrderId:     orderId,
+			Status:      constant.PENDING,
+			Total:       form.Total,
+			CreatedBy:   form.CreatedBy,
+			CreatedDate: time.Now(),
+			UpdatedBy:   form.CreatedBy,
+			UpdatedDate: time.Now(),
+		}
+		_, err = entity.paymentRepo.InsertOne(ctx, payment)
+	} else {
+		_, err = entity.paymentRepo.InsertMany(ctx, newPayments(orderId, form.Payments, shiftId, form.CreatedBy))
 	}
-	_, err = entity.paymentRepo.InsertOne(ctx, payment)
 	if err != nil {
 		return nil, err
 	}
 	return &data, nil
 }
 
-func (entity *orderEntity) GetOrderRange(form form.GetOrderRange) ([]model.Order, error) {
-	logrus.Info("GetOrderRange")
+// newPayments records each tender as its own payment, the total is the part of the order it paid for,
+// PromptPay stays pending until the transfer is confirmed, the payments count towards the cash of the shift that took them
+func newPayments(orderId primitive.ObjectID, payments []form.Payment, shiftId primitive.ObjectID, createdBy string) []interface{} {
+	items := make([]interface{}, len(payments))
+	for i, payment := range payments {
+		status := constant.ACTIVE
+		if payment.Type == constant.PROMPTPAY {
+			status = constant.PENDING
+		}
+		items[i] = model.Payment{
+			Id:          primitive.NewObjectID(),
+			OrderId:     orderId,
+			Status:      status,
+			Amount:      payment.Amount,
+			Total:       utils.RoundPrice(payment.Amount - payment.Change),
+			Change:      payment.Change,
+			Type:        payment.Type,
+			Reference:   payment.Reference,
+			ShiftId:     shiftId,
+			CreatedBy:   createdBy,
+			CreatedDate: time.Now(),
+			UpdatedBy:   createdBy,
+			UpdatedDate: time.Now(),
+		}
+	}
+	return items
+}
+
+var orderSortFields = map[string]bool{
+	"createdDate": true,
+	"updatedDate": true,
+	"total":       true,
+	"totalCost":   true,
+}
+
+func (entity *orderEntity) GetOrders(form form.GetOrders) ([]model.Order, int64, error) {
+	logrus.Info("GetOrders")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
 
-	var items []model.Order
-	cursor, err := entity.orderRepo.Find(ctx, bson.M{"createdDate": bson.M{
-		"$gt": form.StartDate,
-		"$lt": form.EndDate,
-	},
+	filter := bson.M{}
+	createdDate := bson.M{}
+	if !form.StartDate.IsZero() {
+		createdDate["$gte"] = form.StartDate
+	}
+	if !form.EndDate.IsZero() {
+		createdDate["$lt"] = form.EndDate
+	}
+	if len(createdDate) > 0 {
+		filter["createdDate"] = createdDate
+	}
+	if form.Status != "" {
+		filter["status"] = form.Status
+	}
+	if form.CreatedBy != "" {
+		filter["createdBy"] = form.CreatedBy
+	}
+	total := bson.M{}
+	if form.MinTotal != nil {
+		total["$gte"] = *form.MinTotal
+	}
+	if form.MaxTotal != nil {
+		total["$lte"] = *form.MaxTotal
+	}
+	if len(total) > 0 {
+		filter["total"] = total
+	}
+	if form.CustomerId != "" {
+		customerId, _ := primitive.ObjectIDFromHex(form.CustomerId)
+		filter["customerId"] = customerId
+	}
+	if form.ShiftId != "" {
+		shiftId, _ := primitive.ObjectIDFromHex(form.ShiftId)
+		filter["shiftId"] = shiftId
+	}
+	pipeline := []bson.M{{"$match": filter}}
+	// the tender type and the product are looked up per order, so the orders matched stay in the pipeline
+	if form.Type != "" {
+		pipeline = append(pipeline, getOrderLookup("payments", "payment", bson.M{
+			"type":   form.Type,
+			"status": bson.M{"$ne": constant.REFUNDED},
+		})...)
+	}
+	if form.ProductId != "" {
+		productId, _ := primitive.ObjectIDFromHex(form.ProductId)
+		pipeline = append(pipeline, getOrderLookup("order_items", "item", bson.M{"productId": productId})...)
+	}
+
+	sortField := strings.TrimPrefix(form.Sort, "-")
+	sortOrder := 1
+	if strings.HasPrefix(form.Sort, "-") {
+		sortOrder = -1
+	}
+	if !orderSortFields[sortField] {
+		sortField = "createdDate"
+		sortOrder = -1
+	}
+	page := form.Page
+	if page < 1 {
+		page = 1
+	}
+	size := form.Size
+	if size < 1 || size > 100 {
+		size = 20
+	}
+	pipeline = append(pipeline, bson.M{
+		"$facet": bson.M{
+			"count": []bson.M{{"$count": "count"}},
+			"items": []bson.M{
+				{"$sort": bson.D{{Key: sortField, Value: sortOrder}, {Key: "_id", Value: sortOrder}}},
+				{"$skip": (page - 1) * size},
+				{"$limit": size},
+			},
+		},
 	})
+
+	cursor, err := entity.orderRepo.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	for cursor.Next(ctx) {
-		var data model.Order
-		err = cursor.Decode(&data)
-		if err != nil {
-			logrus.Error(err)
-			logrus.Info(cursor.Current)
-		} else {
-			items = append(items, data)
+	var results []struct {
+		Count []struct {
+			Count int64 `bson:"count"`
+		} `bson:"count"`
+		Items []model.Order `bson:"items"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+	var count int64
+	items := []model.Order{}
+	if len(results) > 0 {
+		if len(results[0].Count) > 0 {
+			count = results[0].Count[0].Count
+		}
+		if results[0].Items != nil {
+			items = results[0].Items
 		}
 	}
-	if items == nil {
-		items = []model.Order{}
+	return items, count, nil
+}
+
+// getOrderLookup keeps the orders with at least one document in the collection matching match, as the field name
+// which is dropped again after
+func getOrderLookup(collection string, name string, match bson.M) []bson.M {
+	match["$expr"] = bson.M{"$eq": bson.A{"$orderId", "$$orderId"}}
+	return []bson.M{
+		{
+			"$lookup": bson.M{
+				"from": collection,
+				"let":  bson.M{"orderId": "$_id"},
+				"pipeline": []bson.M{
+					{"$match": match},
+					{"$limit": 1},
+					{"$project": bson.M{"_id": 1}},
+				},
+				"as": name,
+			},
+		},
+		{"$match": bson.M{name + ".0": bson.M{"$exists": true}}},
+		{"$project": bson.M{name: 0}},
 	}
-	return items, nil
 }
 
 func (entity *orderEntity) UpdateTotal() ([]model.Order, error) {
@@ -162,8 +400,8 @@ func (entity *orderEntity) UpdateTotal() ([]model.Order, error) {
 			logrus.Info(cursor.Current)
 		} else {
 			if data.Total == 0 {
-				data.Total = entity.GetTotalOrderById(data.Id.Hex())
-				data.TotalCost = entity.GetTotalCostOrderById(data.Id.Hex())
+				data.Total = entity.GetTotalOrderById(ctx, data.Id.Hex())
+				data.TotalCost = entity.GetTotalCostOrderById(ctx, data.Id.Hex())
 				isReturnNewDoc := options.After
 				opts := &options.FindOneAndUpdateOptions{
 					ReturnDocument: &isReturnNewDoc,
@@ -195,34 +433,31 @@ func (entity *orderEntity) GetOrderById(id string) (*model.Order, error) {
 	return &data, nil
 }
 
-func (entity *orderEntity) UpdateTotalCostOrderById(id string, totalCost float64) (*model.Order, error) {
+func (entity *orderEntity) UpdateTotalCostOrderById(ctx context.Context, id string, totalCost float64, updatedBy string) (*model.Order, error) {
 	logrus.Info("UpdateTotalCostOrderById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
-	data, err := entity.GetOrderById(id)
 	objId, _ := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return nil, err
-	}
-
-	data.TotalCost = totalCost
-	data.UpdatedDate = time.Now()
+	update := bson.M{"$set": bson.M{
+		"totalCost":   totalCost,
+		"updatedBy":   updatedBy,
+		"updatedDate": time.Now(),
+	}}
 
 	isReturnNewDoc := options.After
 	opts := &options.FindOneAndUpdateOptions{
 		ReturnDocument: &isReturnNewDoc,
 	}
-	err = entity.orderRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, bson.M{"$set": data}, opts).Decode(&data)
+	var data model.Order
+	err := entity.orderRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	return &data, nil
 }
 
-func (entity *orderEntity) GetOrderDetailById(id string) (*model.OrderDetail, error) {
+// GetOrderDetailById reads the order with its payments, items and refunds, inside a transaction when ctx
+// is the session
+func (entity *orderEntity) GetOrderDetailById(ctx context.Context, id string) (*model.OrderDetail, error) {
 	logrus.Info("GetOrderDetailById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
 	var data model.OrderDetail
 	objId, _ := primitive.ObjectIDFromHex(id)
 	err := entity.orderRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
@@ -230,51 +465,46 @@ func (entity *orderEntity) GetOrderDetailById(id string) (*model.OrderDetail, er
 		return nil, err
 	}
 
-	payment, err := entity.GetPaymentByOrderId(id)
+	payments, err := entity.getPayments(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	data.Payment = *payment
+	data.Payments = payments
 
-	items, err := entity.GetOrderItemDetailByOrderId(id)
+	items, err := entity.getOrderItemDetails(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	data.Items = items
 
-	return &data, nil
-}
-
-func (entity *orderEntity) RemoveOrderById(id string) (*model.OrderDetail, error) {
-	logrus.Info("RemoveOrderById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
-
-	var data model.OrderDetail
-	objId, _ := primitive.ObjectIDFromHex(id)
-	err := entity.orderRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	refunds, err := entity.getRefunds(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	data.Refunds = refunds
 
-	_, err = entity.orderRepo.DeleteOne(ctx, bson.M{"_id": data.Id})
-	if err != nil {
-		return nil, err
+	invoice, err := entity.GetTaxInvoiceByOrderId(ctx, id)
+	if err == nil {
+		data.TaxInvoice = invoice
 	}
 
-	payment, _ := entity.RemovePaymentByOrderId(id)
-	data.Payment = *payment
-
-	items, _ := entity.RemoveOrderItemByOrderId(id)
-	data.Items = items
-
 	return &data, nil
 }
 
-func (entity *orderEntity) UpdateTotalOrderById(id string) (*model.Order, error) {
-	logrus.Info("UpdateTotalOrderById")
+func (entity *orderEntity) GetOrderDetailByNumber(branch string, orderNumber string) (*model.OrderDetail, error) {
+	logrus.Info("GetOrderDetailByNumber")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
+	var data model.Order
+	err := entity.orderRepo.FindOne(ctx, bson.M{"branch": branch, "orderNumber": orderNumber}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return entity.GetOrderDetailById(ctx, data.Id.Hex())
+}
+
+func (entity *orderEntity) UpdateTotalOrderById(ctx context.Context, id string) (*model.Order, error) {
+	logrus.Info("UpdateTotalOrderById")
 
 	var data model.Order
 	objId, _ := primitive.ObjectIDFromHex(id)
@@ -283,8 +513,12 @@ func (entity *orderEntity) UpdateTotalOrderById(id string) (*model.Order, error)
 		return nil, err
 	}
 
-	data.Total = entity.GetTotalOrderById(id)
-	data.TotalCost = entity.GetTotalCostOrderById(id)
+	data.Total = utils.RoundPrice(entity.GetTotalOrderById(ctx, id))
+	data.TotalCost = entity.GetTotalCostOrderById(ctx, id)
+	err = entity.setAmountsOrderById(ctx, id, &data)
+	if err != nil {
+		return nil, err
+	}
 	data.UpdatedDate = time.Now()
 	isReturnNewDoc := options.After
 	opts := &options.FindOneAndUpdateOptions{
@@ -295,14 +529,41 @@ func (entity *orderEntity) UpdateTotalOrderById(id string) (*model.Order, error)
 		return nil, err
 	}
 
+	_, err = entity.paymentRepo.UpdateMany(ctx, bson.M{"orderId": objId, "status": constant.PENDING}, bson.M{"$set": bson.M{
+		"total":       data.Total,
+		"updatedDate": time.Now(),
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (entity *orderEntity) UpdateStatusById(ctx context.Context, id string, status string, updatedBy string) (*model.Order, error) {
+	logrus.Info("UpdateStatusById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"updatedBy":   updatedBy,
+		"updatedDate": time.Now(),
+	}}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.Order
+	err := entity.orderRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
 	return &data, nil
 }
 
-func (entity *orderEntity) GetTotalOrderById(orderId string) float64 {
+// GetTotalOrderById sums the net item prices, line and promotion discounts are already taken off every price
+func (entity *orderEntity) GetTotalOrderById(ctx context.Context, orderId string) float64 {
 	logrus.Info("GetTotalOrderById")
-	ctx, cancel := utils.InitContext()
 	objId, _ := primitive.ObjectIDFromHex(orderId)
-	defer cancel()
 	pipeline := []bson.M{
 		{
 			"$match": bson.M{
@@ -328,11 +589,64 @@ func (entity *orderEntity) GetTotalOrderById(orderId string) float64 {
 	return result[0]["total"].(float64)
 }
 
-func (entity *orderEntity) GetTotalCostOrderById(orderId string) float64 {
+// setAmountsOrderById sums the item taxes by tax class and the item discounts onto the order, items without a class count as VAT
+func (entity *orderEntity) setAmountsOrderById(ctx context.Context, orderId string, data *model.Order) error {
+	logrus.Info("setAmountsOrderById")
+	objId, _ := primitive.ObjectIDFromHex(orderId)
+	isClass := func(taxClass string) bson.M {
+		return bson.M{"$eq": bson.A{"$taxClass", taxClass}}
+	}
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"orderId": objId,
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":             "",
+				"zeroRatedAmount": bson.M{"$sum": bson.M{"$cond": bson.A{isClass(constant.TaxZero), "$price", 0}}},
+				"exemptAmount":    bson.M{"$sum": bson.M{"$cond": bson.A{isClass(constant.TaxExempt), "$price", 0}}},
+				"vatAmount":       bson.M{"$sum": bson.M{"$ifNull": bson.A{"$vatAmount", 0}}},
+				"total":           bson.M{"$sum": "$price"},
+				"discount":        bson.M{"$sum": "$discount"},
+			},
+		},
+	}
+	var result []struct {
+		ZeroRatedAmount float64 `bson:"zeroRatedAmount"`
+		ExemptAmount    float64 `bson:"exemptAmount"`
+		VatAmount       float64 `bson:"vatAmount"`
+		Total           float64 `bson:"total"`
+		Discount        float64 `bson:"discount"`
+	}
+	cursor, err := entity.orderItemRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	err = cursor.All(ctx, &result)
+	if err != nil {
+		return err
+	}
+	data.VatableAmount = 0
+	data.ZeroRatedAmount = 0
+	data.ExemptAmount = 0
+	data.VatAmount = 0
+	data.Discount = 0
+	if len(result) > 0 {
+		data.ZeroRatedAmount = utils.RoundPrice(result[0].ZeroRatedAmount)
+		data.ExemptAmount = utils.RoundPrice(result[0].ExemptAmount)
+		data.VatAmount = utils.RoundPrice(result[0].VatAmount)
+		data.VatableAmount = utils.RoundPrice(result[0].Total - result[0].ZeroRatedAmount - result[0].ExemptAmount - result[0].VatAmount)
+		data.Discount = utils.RoundPrice(result[0].Discount)
+	}
+	data.GrossTotal = utils.RoundPrice(data.Total + data.Discount)
+	return nil
+}
+
+func (entity *orderEntity) GetTotalCostOrderById(ctx context.Context, orderId string) float64 {
 	logrus.Info("GetTotalCostOrderById")
-	ctx, cancel := utils.InitContext()
 	objId, _ := primitive.ObjectIDFromHex(orderId)
-	defer cancel()
 	pipeline := []bson.M{
 		{
 			"$match": bson.M{
@@ -401,10 +715,8 @@ func (entity *orderEntity) GetOrderItemRange(form form.GetOrderRange) ([]model.O
 	return items, nil
 }
 
-func (entity *orderEntity) GetOrderItemById(id string) (*model.OrderItem, error) {
+func (entity *orderEntity) GetOrderItemById(ctx context.Context, id string) (*model.OrderItem, error) {
 	logrus.Info("GetOrderItemById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
 	var data model.OrderItem
 	objId, _ := primitive.ObjectIDFromHex(id)
 	err := entity.orderItemRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
@@ -414,46 +726,133 @@ func (entity *orderEntity) GetOrderItemById(id string) (*model.OrderItem, error)
 	return &data, nil
 }
 
-func (entity *orderEntity) UpdateOrderItemById(id string, form form.OrderItem) (*model.OrderItem, error) {
+func (entity *orderEntity) CreateOrderItem(ctx context.Context, orderId string, form form.OrderItem) (*model.OrderItem, error) {
+	logrus.Info("CreateOrderItem")
+	objId, _ := primitive.ObjectIDFromHex(orderId)
+	productId, err := primitive.ObjectIDFromHex(form.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	data := model.OrderItem{
+		Id:                primitive.NewObjectID(),
+		OrderId:           objId,
+		ProductId:         productId,
+		Quantity:          form.Quantity,
+		Price:             form.Price,
+		CostPrice:         form.CostPrice,
+		Discount:          form.Discount,
+		TaxClass:          form.TaxClass,
+		VatRate:           form.VatRate,
+		VatAmount:         form.VatAmount,
+		PromotionDiscount: form.PromotionDiscount,
+		Lots:              form.Lots,
+		CreatedBy:         form.UpdatedBy,
+		CreatedDate:       time.Now(),
+		UpdatedBy:         form.UpdatedBy,
+		UpdatedDate:       time.Now(),
+	}
+	_, err = entity.orderItemRepo.InsertOne(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// UpdateOrderItemById replaces the sold line of the item, so the form must carry the full repriced and
+// re-allocated item. The order, product and created fields are left as they are.
+func (entity *orderEntity) UpdateOrderItemById(ctx context.Context, id string, form form.OrderItem) (*model.OrderItem, error) {
 	logrus.Info("UpdateOrderItemById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
-	data, err := entity.GetOrderItemById(id)
 	objId, _ := primitive.ObjectIDFromHex(id)
+	update := bson.M{"$set": bson.M{
+		"quantity":          form.Quantity,
+		"price":             form.Price,
+		"costPrice":         form.CostPrice,
+		"discount":          form.Discount,
+		"taxClass":          form.TaxClass,
+		"vatRate":           form.VatRate,
+		"vatAmount":         form.VatAmount,
+		"promotionDiscount": form.PromotionDiscount,
+		"lots":              form.Lots,
+		"updatedBy":         form.UpdatedBy,
+		"updatedDate":       time.Now(),
+	}}
+
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.OrderItem
+	err := entity.orderItemRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
+	return &data, nil
+}
 
-	data.Discount = form.Discount
-	data.Price = form.Price
-	data.CostPrice = form.CostPrice
-	data.Quantity = form.Quantity
-	data.UpdatedDate = time.Now()
+// UpdateOrderItemPriceById reprices the item for the promotions applied at payment, the quantity, cost and lots
+// stay as they were sold
+func (entity *orderEntity) UpdateOrderItemPriceById(ctx context.Context, id string, form form.OrderItem) (*model.OrderItem, error) {
+	logrus.Info("UpdateOrderItemPriceById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	update := bson.M{"$set": bson.M{
+		"price":             form.Price,
+		"discount":          form.Discount,
+		"promotionDiscount": form.PromotionDiscount,
+		"taxClass":          form.TaxClass,
+		"vatRate":           form.VatRate,
+		"vatAmount":         form.VatAmount,
+		"updatedBy":         form.UpdatedBy,
+		"updatedDate":       time.Now(),
+	}}
 
 	isReturnNewDoc := options.After
 	opts := &options.FindOneAndUpdateOptions{
 		ReturnDocument: &isReturnNewDoc,
 	}
-	err = entity.orderItemRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, bson.M{"$set": data}, opts).Decode(&data)
+	var data model.OrderItem
+	err := entity.orderItemRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	return &data, nil
 }
 
-func (entity *orderEntity) RemoveOrderItemById(id string) (*model.OrderItemDetail, error) {
+// UpdateOrderItemCostById re-costs the item and leaves the rest of it as sold
+func (entity *orderEntity) UpdateOrderItemCostById(ctx context.Context, id string, costPrice float64, updatedBy string) (*model.OrderItem, error) {
+	logrus.Info("UpdateOrderItemCostById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	update := bson.M{"$set": bson.M{
+		"costPrice":   costPrice,
+		"updatedBy":   updatedBy,
+		"updatedDate": time.Now(),
+	}}
+
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.OrderItem
+	err := entity.orderItemRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *orderEntity) RemoveOrderItemById(ctx context.Context, id string) (*model.OrderItemDetail, error) {
 	logrus.Info("RemoveOrderItemById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
 	objId, _ := primitive.ObjectIDFromHex(id)
 	item, err := entity.GetOrderItemDetailById(id)
 	if err != nil {
 		return nil, err
 	}
-	_, err = entity.orderItemRepo.DeleteOne(ctx, bson.M{"_id": objId})
+	deleted, err := entity.orderItemRepo.DeleteOne(ctx, bson.M{"_id": objId})
 	if err != nil {
 		return nil, err
 	}
+	if deleted.DeletedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
 	return item, nil
 }
 
@@ -493,7 +892,7 @@ func (entity *orderEntity) GetOrderItemDetailById(id string) (*model.OrderItemDe
 		}
 	}
 	if items == nil {
-		items = []model.OrderItemDetail{}
+		return nil, mongo.ErrNoDocuments
 	}
 	return &items[0], nil
 }
@@ -502,6 +901,10 @@ func (entity *orderEntity) GetOrderItemDetailByOrderId(orderId string) ([]model.
 	logrus.Info("GetOrderItemByOrderId")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
+	return entity.getOrderItemDetails(ctx, orderId)
+}
+
+func (entity *orderEntity) getOrderItemDetails(ctx context.Context, orderId string) ([]model.OrderItemDetail, error) {
 	objId, _ := primitive.ObjectIDFromHex(orderId)
 	cursor, err := entity.orderItemRepo.Aggregate(ctx, []bson.M{
 		{
@@ -540,36 +943,21 @@ func (entity *orderEntity) GetOrderItemDetailByOrderId(orderId string) ([]model.
 	return items, nil
 }
 
-func (entity *orderEntity) GetOrderItemDetailByOrderProductId(orderId string, productId string) (*model.OrderItemDetail, error) {
-	logrus.Info("GetOrderItemDetailByOrderProductId")
+func (entity *orderEntity) GetOrderItemByProductId(productId string) ([]model.OrderItem, error) {
+	logrus.Info("GetOrderItemByProductId")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
-	objId, _ := primitive.ObjectIDFromHex(orderId)
-	productObjId, _ := primitive.ObjectIDFromHex(productId)
-	cursor, err := entity.orderItemRepo.Aggregate(ctx, []bson.M{
-		{
-			"$match": bson.M{
-				"orderId":   objId,
-				"productId": productObjId,
-			},
-		},
-		{
-			"$lookup": bson.M{
-				"from":         "products",
-				"localField":   "productId",
-				"foreignField": "_id",
-				"as":           "product",
-			},
-		},
-		{"$unwind": "$product"},
+	objId, _ := primitive.ObjectIDFromHex(productId)
+	cursor, err := entity.orderItemRepo.Find(ctx, bson.M{
+		"productId": objId,
 	})
 
 	if err != nil {
 		return nil, err
 	}
-	var items []model.OrderItemDetail
+	var items []model.OrderItem
 	for cursor.Next(ctx) {
-		var data model.OrderItemDetail
+		var data model.OrderItem
 		err = cursor.Decode(&data)
 		if err != nil {
 			logrus.Error(err)
@@ -579,26 +967,28 @@ func (entity *orderEntity) GetOrderItemDetailByOrderProductId(orderId string, pr
 		}
 	}
 	if items == nil {
-		items = []model.OrderItemDetail{}
+		items = []model.OrderItem{}
 	}
-	return &items[0], nil
+	return items, nil
 }
 
-func (entity *orderEntity) GetOrderItemByProductId(productId string) ([]model.OrderItem, error) {
-	logrus.Info("GetOrderItemByProductId")
+func (entity *orderEntity) GetPaymentsByOrderId(orderId string) ([]model.Payment, error) {
+	logrus.Info("GetPaymentsByOrderId")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
-	objId, _ := primitive.ObjectIDFromHex(productId)
-	cursor, err := entity.orderItemRepo.Find(ctx, bson.M{
-		"productId": objId,
-	})
+	return entity.getPayments(ctx, orderId)
+}
 
+func (entity *orderEntity) getPayments(ctx context.Context, orderId string) ([]model.Payment, error) {
+	objId, _ := primitive.ObjectIDFromHex(orderId)
+	opts := options.Find().SetSort(bson.D{{Key: "createdDate", Value: 1}})
+	cursor, err := entity.paymentRepo.Find(ctx, bson.M{"orderId": objId, "status": bson.M{"$ne": constant.REFUNDED}}, opts)
 	if err != nil {
 		return nil, err
 	}
-	var items []model.OrderItem
+	var items []model.Payment
 	for cursor.Next(ctx) {
-		var data model.OrderItem
+		var data model.Payment
 		err = cursor.Decode(&data)
 		if err != nil {
 			logrus.Error(err)
@@ -608,70 +998,471 @@ func (entity *orderEntity) GetOrderItemByProductId(productId string) ([]model.Or
 		}
 	}
 	if items == nil {
-		items = []model.OrderItem{}
+		items = []model.Payment{}
 	}
 	return items, nil
 }
 
-func (entity *orderEntity) RemoveOrderItemByOrderId(orderId string) ([]model.OrderItemDetail, error) {
-	logrus.Info("RemoveOrderItemByOrderId")
+func (entity *orderEntity) GetPaymentById(id string) (*model.Payment, error) {
+	logrus.Info("GetPaymentById")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
-	objId, _ := primitive.ObjectIDFromHex(orderId)
-	items, err := entity.GetOrderItemDetailByOrderId(orderId)
+	var data model.Payment
+	objId, _ := primitive.ObjectIDFromHex(id)
+	err := entity.paymentRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
-	_, err = entity.orderItemRepo.DeleteMany(ctx, bson.M{"orderId": objId})
+	return &data, nil
+}
+
+// ConfirmPaymentById marks a pending PromptPay payment paid, fails with mongo.ErrNoDocuments when it is not pending
+// and with ErrOrderNotPayable when its order was voided or refunded meanwhile
+func (entity *orderEntity) ConfirmPaymentById(id string, form form.ConfirmPayment) (*model.Payment, error) {
+	logrus.Info("ConfirmPaymentById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var payment model.Payment
+	err := entity.paymentRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&payment)
 	if err != nil {
 		return nil, err
 	}
-	return items, nil
+	count, err := entity.orderRepo.CountDocuments(ctx, bson.M{
+		"_id":    payment.OrderId,
+		"status": bson.M{"$in": bson.A{constant.OPEN, constant.ACTIVE}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrOrderNotPayable
+	}
+	set := bson.M{
+		"status":      constant.PAID,
+		"updatedBy":   form.UpdatedBy,
+		"updatedDate": time.Now(),
+	}
+	if form.Reference != "" {
+		set["reference"] = form.Reference
+	}
+	filter := bson.M{"_id": objId, "status": constant.PENDING, "type": constant.PROMPTPAY}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.Payment
+	err = entity.paymentRepo.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// AddRefundedQuantityById fails with mongo.ErrNoDocuments when the item has fewer units left to refund
+func (entity *orderEntity) AddRefundedQuantityById(ctx context.Context, id string, quantity int) (*model.OrderItem, error) {
+	logrus.Info("AddRefundedQuantityById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{
+		"_id": objId,
+		"$expr": bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$refundedQuantity", 0}}, quantity}},
+			"$quantity",
+		}},
+	}
+	update := bson.M{
+		"$inc": bson.M{"refundedQuantity": quantity},
+		"$set": bson.M{"updatedDate": time.Now()},
+	}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.OrderItem
+	err := entity.orderItemRepo.FindOneAndUpdate(ctx, filter, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// CreateRefund pays the refund back through its tenders, one refund payment each
+func (entity *orderEntity) CreateRefund(ctx context.Context, refund model.Refund) (*model.Refund, error) {
+	logrus.Info("CreateRefund")
+	for index, tender := range refund.Tenders {
+		refundPayment := model.Payment{
+			Id:           primitive.NewObjectID(),
+			OrderId:      refund.OrderId,
+			Status:       constant.REFUNDED,
+			Amount:       -tender.Amount,
+			Total:        -tender.Amount,
+			Type:         tender.Type,
+			PaymentRefId: tender.PaymentId,
+			Reason:       refund.Reason,
+			ShiftId:      refund.ShiftId,
+			CreatedBy:    refund.CreatedBy,
+			CreatedDate:  time.Now(),
+			UpdatedBy:    refund.CreatedBy,
+			UpdatedDate:  time.Now(),
+		}
+		_, err := entity.paymentRepo.InsertOne(ctx, refundPayment)
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			refund.PaymentId = refundPayment.Id
+		}
+	}
+
+	refund.Id = primitive.NewObjectID()
+	refund.CreatedDate = time.Now()
+	_, err := entity.refundRepo.InsertOne(ctx, refund)
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
 }
 
-func (entity *orderEntity) RemoveOrderItemByOrderProductId(orderId string, productId string) (*model.OrderItemDetail, error) {
-	logrus.Info("RemoveOrderItemByOrderProductId")
+func (entity *orderEntity) GetRefundsByOrderId(orderId string) ([]model.Refund, error) {
+	logrus.Info("GetRefundsByOrderId")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
+	return entity.getRefunds(ctx, orderId)
+}
+
+func (entity *orderEntity) getRefunds(ctx context.Context, orderId string) ([]model.Refund, error) {
 	objId, _ := primitive.ObjectIDFromHex(orderId)
-	productObjId, _ := primitive.ObjectIDFromHex(productId)
-	item, err := entity.GetOrderItemDetailByOrderProductId(orderId, productId)
+	cursor, err := entity.refundRepo.Find(ctx, bson.M{"orderId": objId})
 	if err != nil {
 		return nil, err
 	}
-	_, err = entity.orderItemRepo.DeleteOne(ctx, bson.M{"orderId": objId, "productId": productObjId})
+	var items []model.Refund
+	for cursor.Next(ctx) {
+		var data model.Refund
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+			logrus.Info(cursor.Current)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.Refund{}
+	}
+	return items, nil
+}
+
+// PayOrderById settles an open order, its tenders replace the pending payment, fails with mongo.ErrNoDocuments when the order is not open
+func (entity *orderEntity) PayOrderById(ctx context.Context, id string, form form.PayOrder) (*model.Order, error) {
+	logrus.Info("PayOrderById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	set := bson.M{
+		"status":         constant.ACTIVE,
+		"type":           form.Type,
+		"promotions":     form.Promotions,
+		"pointsEarned":   form.PointsEarned,
+		"pointsRedeemed": form.PointsRedeemed,
+		"updatedBy":      form.UpdatedBy,
+		"updatedDate":    time.Now(),
+	}
+	if form.CustomerId != "" {
+		customerId, _ := primitive.ObjectIDFromHex(form.CustomerId)
+		set["customerId"] = customerId
+	}
+	shiftId, _ := primitive.ObjectIDFromHex(form.ShiftId)
+	if form.ShiftId != "" {
+		set["shiftId"] = shiftId
+	}
+	update := bson.M{"$set": set}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.Order
+	err := entity.orderRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId, "status": constant.OPEN}, update, opts).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
-	return item, nil
+
+	_, err = entity.paymentRepo.DeleteMany(ctx, bson.M{"orderId": objId, "status": constant.PENDING})
+	if err != nil {
+		return nil, err
+	}
+	_, err = entity.paymentRepo.InsertMany(ctx, newPayments(objId, form.Payments, shiftId, form.UpdatedBy))
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
 }
 
-func (entity *orderEntity) GetPaymentByOrderId(orderId string) (*model.Payment, error) {
-	logrus.Info("GetPaymentByOrderId")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
-	var data model.Payment
+func (entity *orderEntity) CreateTaxInvoice(ctx context.Context, invoice model.TaxInvoice) (*model.TaxInvoice, error) {
+	logrus.Info("CreateTaxInvoice")
+	invoice.Id = primitive.NewObjectID()
+	invoice.CreatedDate = time.Now()
+	_, err := entity.taxInvoiceRepo.InsertOne(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (entity *orderEntity) GetTaxInvoiceByOrderId(ctx context.Context, orderId string) (*model.TaxInvoice, error) {
+	logrus.Info("GetTaxInvoiceByOrderId")
+	var data model.TaxInvoice
 	objId, _ := primitive.ObjectIDFromHex(orderId)
-	err := entity.paymentRepo.FindOne(ctx, bson.M{"orderId": objId}).Decode(&data)
+	err := entity.taxInvoiceRepo.FindOne(ctx, bson.M{"orderId": objId}).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
 	return &data, nil
 }
 
-func (entity *orderEntity) RemovePaymentByOrderId(orderId string) (*model.Payment, error) {
-	logrus.Info("RemovePaymentByOrderId")
+// getVatSummary groups sales or refunds by their Bangkok calendar day
+func getVatSummary(ctx context.Context, collection *mongo.Collection, match bson.M, count string) ([]model.VatSummary, error) {
+	cursor, err := collection.Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id": bson.M{"$dateToString": bson.M{
+					"format":   "%Y-%m-%d",
+					"date":     "$createdDate",
+					"timezone": "Asia/Bangkok",
+				}},
+				count:             bson.M{"$sum": 1},
+				"vatableAmount":   bson.M{"$sum": "$vatableAmount"},
+				"zeroRatedAmount": bson.M{"$sum": "$zeroRatedAmount"},
+				"exemptAmount":    bson.M{"$sum": "$exemptAmount"},
+				"vatAmount":       bson.M{"$sum": "$vatAmount"},
+				"total":           bson.M{"$sum": "$total"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var items []model.VatSummary
+	err = cursor.All(ctx, &items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetVatReport nets sales against refunds per day, a void shows up as a refund on the day it happened
+func (entity *orderEntity) GetVatReport(form form.GetVatReport) (*model.VatReport, error) {
+	logrus.Info("GetVatReport")
 	ctx, cancel := utils.InitContext()
 	defer cancel()
-	var data model.Payment
-	objId, _ := primitive.ObjectIDFromHex(orderId)
-	err := entity.paymentRepo.FindOne(ctx, bson.M{"orderId": objId}).Decode(&data)
+	createdDate := bson.M{
+		"$gte": form.StartDate,
+		"$lt":  form.EndDate,
+	}
+	sales, err := getVatSummary(ctx, entity.orderRepo, bson.M{
+		"createdDate": createdDate,
+		"status":      bson.M{"$ne": constant.OPEN},
+	}, "orders")
 	if err != nil {
 		return nil, err
 	}
-	_, err = entity.paymentRepo.DeleteMany(ctx, bson.M{"orderId": objId})
+	refunds, err := getVatSummary(ctx, entity.refundRepo, bson.M{"createdDate": createdDate}, "refunds")
+	if err != nil {
+		return nil, err
+	}
+
+	days := map[string]*model.VatSummary{}
+	var dates []string
+	getDay := func(date string) *model.VatSummary {
+		day, ok := days[date]
+		if !ok {
+			day = &model.VatSummary{Date: date}
+			days[date] = day
+			dates = append(dates, date)
+		}
+		return day
+	}
+	for _, sale := range sales {
+		day := getDay(sale.Date)
+		day.Orders = sale.Orders
+		day.VatableAmount += sale.VatableAmount
+		day.ZeroRatedAmount += sale.ZeroRatedAmount
+		day.ExemptAmount += sale.ExemptAmount
+		day.VatAmount += sale.VatAmount
+		day.Total += sale.Total
+	}
+	for _, refund := range refunds {
+		day := getDay(refund.Date)
+		day.Refunds = refund.Refunds
+		day.VatableAmount -= refund.VatableAmount
+		day.ZeroRatedAmount -= refund.ZeroRatedAmount
+		day.ExemptAmount -= refund.ExemptAmount
+		day.VatAmount -= refund.VatAmount
+		day.Total -= refund.Total
+	}
+	sort.Strings(dates)
+
+	report := model.VatReport{Days: []model.VatSummary{}}
+	for _, date := range dates {
+		day := days[date]
+		day.VatableAmount = utils.RoundPrice(day.VatableAmount)
+		day.ZeroRatedAmount = utils.RoundPrice(day.ZeroRatedAmount)
+		day.ExemptAmount = utils.RoundPrice(day.ExemptAmount)
+		day.VatAmount = utils.RoundPrice(day.VatAmount)
+		day.Total = utils.RoundPrice(day.Total)
+		report.Days = append(report.Days, *day)
+		report.Total.Orders += day.Orders
+		report.Total.Refunds += day.Refunds
+		report.Total.VatableAmount += day.VatableAmount
+		report.Total.ZeroRatedAmount += day.ZeroRatedAmount
+		report.Total.ExemptAmount += day.ExemptAmount
+		report.Total.VatAmount += day.VatAmount
+		report.Total.Total += day.Total
+	}
+	report.Total.VatableAmount = utils.RoundPrice(report.Total.VatableAmount)
+	report.Total.ZeroRatedAmount = utils.RoundPrice(report.Total.ZeroRatedAmount)
+	report.Total.ExemptAmount = utils.RoundPrice(report.Total.ExemptAmount)
+	report.Total.VatAmount = utils.RoundPrice(report.Total.VatAmount)
+	report.Total.Total = utils.RoundPrice(report.Total.Total)
+
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: 1}})
+	cursor, err := entity.taxInvoiceRepo.Find(ctx, bson.M{"createdDate": createdDate}, opts)
 	if err != nil {
 		return nil, err
 	}
+	for cursor.Next(ctx) {
+		var data model.TaxInvoice
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+			logrus.Info(cursor.Current)
+		} else {
+			report.TaxInvoices = append(report.TaxInvoices, data)
+		}
+	}
+	if report.TaxInvoices == nil {
+		report.TaxInvoices = []model.TaxInvoice{}
+	}
+	return &report, nil
+}
+
+// GetPurchaseHistory sums what a customer bought over paid orders, refunds are taken off and voided orders left out
+func (entity *orderEntity) GetPurchaseHistory(customerId string) (*model.PurchaseHistory, error) {
+	logrus.Info("GetPurchaseHistory")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(customerId)
+	statuses := bson.A{constant.OPEN, constant.VOIDED}
+
+	var summary []model.PurchaseHistory
+	cursor, err := entity.orderRepo.Aggregate(ctx, []bson.M{
+		{
+			"$match": bson.M{
+				"customerId": objId,
+				"status":     bson.M{"$nin": statuses},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "refunds",
+				"localField":   "_id",
+				"foreignField": "orderId",
+				"as":           "refunds",
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":            "",
+				"orders":         bson.M{"$sum": 1},
+				"grossTotal":     bson.M{"$sum": "$grossTotal"},
+				"discount":       bson.M{"$sum": "$discount"},
+				"refund":         bson.M{"$sum": bson.M{"$sum": "$refunds.total"}},
+				"total":          bson.M{"$sum": "$total"},
+				"firstOrderDate": bson.M{"$min": "$createdDate"},
+				"lastOrderDate":  bson.M{"$max": "$createdDate"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &summary)
+	if err != nil {
+		return nil, err
+	}
+	data := model.PurchaseHistory{}
+	if len(summary) > 0 {
+		data = summary[0]
+		data.GrossTotal = utils.RoundPrice(data.GrossTotal)
+		data.Discount = utils.RoundPrice(data.Discount)
+		data.Refund = utils.RoundPrice(data.Refund)
+		data.Total = utils.RoundPrice(data.Total - data.Refund)
+	}
+
+	orderIds, err := entity.orderRepo.Distinct(ctx, "_id", bson.M{
+		"customerId": objId,
+		"status":     bson.M{"$nin": statuses},
+	})
+	if err != nil {
+		return nil, err
+	}
+	kept := bson.M{"$subtract": bson.A{"$quantity", bson.M{"$ifNull": bson.A{"$refundedQuantity", 0}}}}
+	cursor, err = entity.orderItemRepo.Aggregate(ctx, []bson.M{
+		{
+			"$match": bson.M{
+				"orderId": bson.M{"$in": orderIds},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "orders",
+				"localField":   "orderId",
+				"foreignField": "_id",
+				"as":           "order",
+			},
+		},
+		{"$unwind": "$order"},
+		{
+			"$group": bson.M{
+				"_id":      "$productId",
+				"quantity": bson.M{"$sum": kept},
+				"total": bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$gt": bson.A{"$quantity", 0}},
+					bson.M{"$divide": bson.A{bson.M{"$multiply": bson.A{"$price", kept}}, "$quantity"}},
+					0,
+				}}},
+				"lastOrderDate": bson.M{"$max": "$order.createdDate"},
+			},
+		},
+		{"$match": bson.M{"quantity": bson.M{"$gt": 0}}},
+		{
+			"$lookup": bson.M{
+				"from":         "products",
+				"localField":   "_id",
+				"foreignField": "_id",
+				"as":           "product",
+			},
+		},
+		{"$unwind": bson.M{"path": "$product", "preserveNullAndEmptyArrays": true}},
+		{"$addFields": bson.M{"name": "$product.name", "unit": "$product.unit"}},
+		{"$sort": bson.D{{Key: "quantity", Value: -1}, {Key: "lastOrderDate", Value: -1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var products []model.ProductPurchase
+	for cursor.Next(ctx) {
+		var item model.ProductPurchase
+		err = cursor.Decode(&item)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			item.Total = utils.RoundPrice(item.Total)
+			products = append(products, item)
+		}
+	}
+	if products == nil {
+		products = []model.ProductPurchase{}
+	}
+	data.Products = products
 	return &data, nil
 }
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	repository2 "devper/app/featues/product/repository"
+	"devper/db"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+func AddOrderItem(transaction db.ITransaction, orderEntity repository.IOrder, productEntity repository2.IProduct) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		request := form.OrderItem{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		order, err := getOpenOrder(orderEntity, orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := calculateOrderItem(productEntity, &request, order.VatMode); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := checkStock(productEntity, []form.OrderItem{request}); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		var result *model.Order
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			item := request
+			err := deductOrderItem(sessionCtx, productEntity, &item, getOrderMovement(constant.StockSale, order.Id, order.OrderNumber, request.UpdatedBy))
+			if err != nil {
+				return err
+			}
+			_, err = orderEntity.CreateOrderItem(sessionCtx, orderId, item)
+			if err != nil {
+				return err
+			}
+			order, err := orderEntity.UpdateTotalOrderById(sessionCtx, orderId)
+			if err != nil {
+				return err
+			}
+			if order.Status != constant.OPEN {
+				return errOrderNotOpen
+			}
+			result = order
+			return nil
+		})
+		if errors.Is(err, repository2.ErrInsufficientStock) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	"devper/app/featues/promotion/model"
+	repository4 "devper/app/featues/promotion/repository"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// getPromotions lists the automatic promotions running now plus the coupon, when one is given
+func getPromotions(promotionEntity repository4.IPromotion, coupon string) ([]model.Promotion, error) {
+	now := time.Now()
+	promotions, err := promotionEntity.GetActivePromotions(now)
+	if err != nil {
+		return nil, err
+	}
+	if coupon == "" {
+		return promotions, nil
+	}
+	promotion, err := promotionEntity.GetPromotionByCode(coupon)
+	if err != nil {
+		return nil, fmt.Errorf("coupon %s not found", coupon)
+	}
+	if !promotion.IsAvailable(now) {
+		return nil, fmt.Errorf("coupon %s is not available", coupon)
+	}
+	return append(promotions, *promotion), nil
+}
+
+// applyPromotions takes the promotion discounts off the items, line promotions go first
+// and order promotions are shared across the lines by their net amount
+func applyPromotions(items []form.OrderItem, promotions []model.Promotion, coupon string, vatMode string) ([]model.AppliedPromotion, error) {
+	applied := []model.AppliedPromotion{}
+	subtotal := getItemsNet(items)
+	for _, promotion := range promotions {
+		if promotion.Type != constant.PromotionBuyXGetY && promotion.Type != constant.PromotionCategory {
+			continue
+		}
+		if subtotal < promotion.MinTotal {
+			continue
+		}
+		amount := 0.0
+		for index := range items {
+			item := &items[index]
+			switch promotion.Type {
+			case constant.PromotionBuyXGetY:
+				set := promotion.BuyQuantity + promotion.FreeQuantity
+				if set <= 0 || item.ProductId != promotion.ProductId.Hex() {
+					continue
+				}
+				free := item.Quantity / set * promotion.FreeQuantity
+				amount += discountItem(item, item.Gross/float64(item.Quantity)*float64(free))
+			case constant.PromotionCategory:
+				if item.Category == "" || !strings.EqualFold(item.Category, promotion.Category) {
+					continue
+				}
+				discount := (item.Gross - item.Discount) * promotion.Value / 100
+				if promotion.MaxDiscount > 0 {
+					discount = math.Min(discount, promotion.MaxDiscount-amount)
+				}
+				amount += discountItem(item, discount)
+			}
+		}
+		applied = appendPromotion(applied, promotion, amount)
+	}
+	for _, promotion := range promotions {
+		if promotion.Type != constant.PromotionPercent && promotion.Type != constant.PromotionFixed {
+			continue
+		}
+		net := getItemsNet(items)
+		if net <= 0 || net < promotion.MinTotal {
+			continue
+		}
+		amount := promotion.Value
+		if promotion.Type == constant.PromotionPercent {
+			amount = net * promotion.Value / 100
+			if promotion.MaxDiscount > 0 {
+				amount = math.Min(amount, promotion.MaxDiscount)
+			}
+		}
+		amount = utils.RoundPrice(math.Min(amount, net))
+		applied = appendPromotion(applied, promotion, allocateDiscount(items, amount, net))
+	}
+	if coupon != "" && !hasCoupon(applied, coupon) {
+		return nil, fmt.Errorf("coupon %s does not apply to this order", coupon)
+	}
+	for index := range items {
+		item := &items[index]
+		item.Price = utils.RoundPrice(item.Gross - item.Discount)
+		calculateItemTax(item, item.TaxClass, vatMode)
+	}
+	return applied, nil
+}
+
+// allocateDiscount shares an order discount across the items by their net amount, the last item takes the rounding
+func allocateDiscount(items []form.OrderItem, amount float64, net float64) float64 {
+	remaining := amount
+	last := -1
+	for index := range items {
+		if items[index].Gross-items[index].Discount > 0 {
+			last = index
+		}
+	}
+	for index := range items {
+		item := &items[index]
+		share := utils.RoundPrice(amount * (item.Gross - item.Discount) / net)
+		if index == last {
+			share = remaining
+		}
+		remaining = utils.RoundPrice(remaining - discountItem(item, share))
+	}
+	return utils.RoundPrice(amount - remaining)
+}
+
+// discountItem takes up to amount off the item and returns what was taken
+func discountItem(item *form.OrderItem, amount float64) float64 {
+	amount = utils.RoundPrice(math.Min(amount, item.Gross-item.Discount))
+	if amount <= 0 {
+		return 0
+	}
+	item.Discount = utils.RoundPrice(item.Discount + amount)
+	item.PromotionDiscount = utils.RoundPrice(item.PromotionDiscount + amount)
+	return amount
+}
+
+func getItemsNet(items []form.OrderItem) float64 {
+	net := 0.0
+	for _, item := range items {
+		net += item.Gross - item.Discount
+	}
+	return utils.RoundPrice(net)
+}
+
+func appendPromotion(applied []model.AppliedPromotion, promotion model.Promotion, amount float64) []model.AppliedPromotion {
+	if amount <= 0 {
+		return applied
+	}
+	return append(applied, model.AppliedPromotion{
+		PromotionId: promotion.Id,
+		Name:        promotion.Name,
+		Code:        promotion.Code,
+		Amount:      utils.RoundPrice(amount),
+	})
+}
+
+func hasCoupon(applied []model.AppliedPromotion, coupon string) bool {
+	for _, promotion := range applied {
+		if strings.EqualFold(promotion.Code, coupon) {
+			return true
+		}
+	}
+	return false
+}
+
+// usePromotions counts one use of every applied promotion, inside the order transaction so a used up coupon fails the order
+func usePromotions(ctx context.Context, promotionEntity repository4.IPromotion, applied []model.AppliedPromotion) error {
+	for _, promotion := range applied {
+		if err := promotionEntity.UsePromotionById(ctx, promotion.PromotionId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sumDiscount fills the gross and discount of the order from its items
+func sumDiscount(request *form.Order) {
+	discount := 0.0
+	for _, item := range request.Items {
+		discount += item.Discount
+	}
+	request.Discount = utils.RoundPrice(discount)
+	request.GrossTotal = utils.RoundPrice(request.Total + request.Discount)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	"devper/app/featues/promotion/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+// getPromotionItems is drinkQuantity drinks at 50 each, a drink at 30 and a snack at 10, priced VAT inclusive
+func getPromotionItems(drinkQuantity int) []form.OrderItem {
+	items := []form.OrderItem{
+		{ProductId: drinkId, Quantity: drinkQuantity, Gross: 50 * float64(drinkQuantity), Category: "DRINK"},
+		{ProductId: milkId, Quantity: 1, Gross: 30, Category: "DRINK"},
+		{ProductId: snackId, Quantity: 1, Gross: 10, Category: "SNACK"},
+	}
+	for index := range items {
+		items[index].Price = items[index].Gross
+		items[index].TaxClass = constant.TaxVat
+	}
+	return items
+}
+
+func TestApplyPromotions(t *testing.T) {
+	drinkObjectId, _ := primitive.ObjectIDFromHex(drinkId)
+	tests := []struct {
+		name          string
+		drinkQuantity int
+		promotions    []model.Promotion
+		coupon        string
+		wantDiscounts []float64
+		wantApplied   []float64
+		wantErr       bool
+	}{
+		{
+			name:          "fixed shared by net, the last line takes the rounding",
+			drinkQuantity: 1,
+			promotions:    []model.Promotion{{Type: constant.PromotionFixed, Value: 10}},
+			wantDiscounts: []float64{5.56, 3.33, 1.11},
+			wantApplied:   []float64{10},
+		},
+		{
+			name:          "percent capped by max discount",
+			drinkQuantity: 1,
+			promotions:    []model.Promotion{{Type: constant.PromotionPercent, Value: 50, MaxDiscount: 20}},
+			wantDiscounts: []float64{11.11, 6.67, 2.22},
+			wantApplied:   []float64{20},
+		},
+		{
+			name:          "fixed over the order takes the whole order",
+			drinkQuantity: 1,
+			promotions:    []model.Promotion{{Type: constant.PromotionFixed, Value: 100}},
+			wantDiscounts: []float64{50, 30, 10},
+			wantApplied:   []float64{90},
+		},
+		{
+			name:          "buy two get one free",
+			drinkQuantity: 3,
+			promotions:    []model.Promotion{{Type: constant.PromotionBuyXGetY, ProductId: drinkObjectId, BuyQuantity: 2, FreeQuantity: 1}},
+			wantDiscounts: []float64{50, 0, 0},
+			wantApplied:   []float64{50},
+		},
+		{
+			name:          "category percent capped by max discount across lines",
+			drinkQuantity: 1,
+			promotions:    []model.Promotion{{Type: constant.PromotionCategory, Category: "drink", Value: 10, MaxDiscount: 6}},
+			wantDiscounts: []float64{5, 1, 0},
+			wantApplied:   []float64{6},
+		},
+		{
+			name:          "line promotions go before order promotions",
+			drinkQuantity: 3,
+			promotions: []model.Promotion{
+				{Type: constant.PromotionPercent, Value: 10},
+				{Type: constant.PromotionBuyXGetY, ProductId: drinkObjectId, BuyQuantity: 2, FreeQuantity: 1},
+			},
+			wantDiscounts: []float64{60, 3, 1},
+			wantApplied:   []float64{50, 14},
+		},
+		{
+			name:          "below the minimum total",
+			drinkQuantity: 1,
+			promotions:    []model.Promotion{{Type: constant.PromotionFixed, Value: 10, MinTotal: 100}},
+			wantDiscounts: []float64{0, 0, 0},
+			wantApplied:   []float64{},
+		},
+		{
+			name:          "coupon applied",
+			drinkQuantity: 1,
+			promotions:    []model.Promotion{{Type: constant.PromotionFixed, Value: 9, Code: "SAVE9"}},
+			coupon:        "save9",
+			wantDiscounts: []float64{5, 3, 1},
+			wantApplied:   []float64{9},
+		},
+		{
+			name:          "coupon that does not apply",
+			drinkQuantity: 1,
+			promotions:    []model.Promotion{{Type: constant.PromotionFixed, Value: 9, Code: "SAVE9", MinTotal: 100}},
+			coupon:        "SAVE9",
+			wantErr:       true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := getPromotionItems(test.drinkQuantity)
+			applied, err := applyPromotions(items, test.promotions, test.coupon, constant.VatInclusive)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("applied %v, want an error", applied)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(applied) != len(test.wantApplied) {
+				t.Fatalf("applied %v, want amounts %v", applied, test.wantApplied)
+			}
+			for index, amount := range test.wantApplied {
+				if applied[index].Amount != amount {
+					t.Errorf("applied[%d] = %.2f, want %.2f", index, applied[index].Amount, amount)
+				}
+			}
+			for index, discount := range test.wantDiscounts {
+				item := items[index]
+				if item.Discount != discount || item.PromotionDiscount != discount {
+					t.Errorf("items[%d] discount %.2f promotion %.2f, want %.2f", index, item.Discount, item.PromotionDiscount, discount)
+				}
+				if price := utils.RoundPrice(item.Gross - discount); item.Price != price {
+					t.Errorf("items[%d] price %.2f, want %.2f", index, item.Price, price)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/promotion/model"
+	repository4 "devper/app/featues/promotion/repository"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const priceTolerance = 0.01
+
+// calculateOrderItem prices the item from the product including VAT, a client price is only checked against it
+func calculateOrderItem(productEntity repository2.IProduct, item *form.OrderItem, vatMode string) error {
+	if item.Quantity <= 0 {
+		return errors.New("quantity must be greater than zero")
+	}
+	product, err := productEntity.GetProductById(item.ProductId)
+	if err != nil {
+		return fmt.Errorf("product %s not found", item.ProductId)
+	}
+	gross := product.Price * float64(item.Quantity)
+	if item.Discount < 0 || item.Discount > gross {
+		return fmt.Errorf("discount of %s is invalid", product.Name)
+	}
+	clientPrice := item.Price
+	item.Category = product.Category
+	item.Gross = utils.RoundPrice(gross)
+	item.PromotionDiscount = 0
+	item.Price = utils.RoundPrice(gross - item.Discount)
+	calculateItemTax(item, product.GetTaxClass(), vatMode)
+	if clientPrice != 0 && math.Abs(clientPrice-item.Price) > priceTolerance {
+		return fmt.Errorf("price of %s does not match, expected %.2f", product.Name, item.Price)
+	}
+	return nil
+}
+
+// calculateOrder prices every item from the product and takes off the promotions, client totals are only checked against it.
+// A held order gets its promotions when it is paid
+func calculateOrder(productEntity repository2.IProduct, promotionEntity repository4.IPromotion, request *form.Order) error {
+	request.VatMode = getVatMode()
+	for index := range request.Items {
+		if err := calculateOrderItem(productEntity, &request.Items[index], request.VatMode); err != nil {
+			return err
+		}
+	}
+	request.Promotions = []model.AppliedPromotion{}
+	if !request.Hold {
+		promotions, err := getPromotions(promotionEntity, request.Coupon)
+		if err != nil {
+			return err
+		}
+		request.Promotions, err = applyPromotions(request.Items, promotions, request.Coupon, request.VatMode)
+		if err != nil {
+			return err
+		}
+	}
+	total := 0.0
+	for _, item := range request.Items {
+		total += item.Price
+	}
+	total = utils.RoundPrice(total)
+	if request.Total != 0 && math.Abs(request.Total-total) > priceTolerance {
+		return fmt.Errorf("total does not match, expected %.2f", total)
+	}
+	request.Total = total
+	calculateOrderTax(request)
+	sumDiscount(request)
+	if request.Hold {
+		request.Amount = 0
+		request.Change = 0
+		request.Payments = nil
+		return nil
+	}
+	request.Payments = getTenders(request.Payments, request.Amount, request.Type)
+	amount, err := calculateTenders(total, request.Payments, &request.Change)
+	if err != nil {
+		return err
+	}
+	request.Amount = amount
+	request.Type = getPaymentType(request.Payments)
+	return nil
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/order/form"
+	model2 "devper/app/featues/product/model"
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/promotion/model"
+	repository4 "devper/app/featues/promotion/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+	"time"
+)
+
+// stubProduct prices from the products in memory, any other IProduct method panics on the nil interface
+type stubProduct struct {
+	repository2.IProduct
+	products map[string]model2.Product
+}
+
+func (stub *stubProduct) GetProductById(id string) (*model2.Product, error) {
+	product, ok := stub.products[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &product, nil
+}
+
+// stubPromotion runs the same promotions whatever the date
+type stubPromotion struct {
+	repository4.IPromotion
+	promotions []model.Promotion
+}
+
+func (stub *stubPromotion) GetActivePromotions(date time.Time) ([]model.Promotion, error) {
+	return stub.promotions, nil
+}
+
+func (stub *stubPromotion) GetPromotionByCode(code string) (*model.Promotion, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+var (
+	drinkId = primitive.NewObjectID().Hex()
+	snackId = primitive.NewObjectID().Hex()
+	milkId  = primitive.NewObjectID().Hex()
+)
+
+func newStubProduct() *stubProduct {
+	return &stubProduct{products: map[string]model2.Product{
+		drinkId: {Name: "Drink", Price: 50, Category: "DRINK"},
+		snackId: {Name: "Snack", Price: 30, Category: "SNACK", TaxClass: constant.TaxVat},
+		milkId:  {Name: "Milk", Price: 40, Category: "DRINK", TaxClass: constant.TaxZero},
+	}}
+}
+
+func TestCalculateOrderItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		item      form.OrderItem
+		vatMode   string
+		wantPrice float64
+		wantVat   float64
+		wantErr   bool
+	}{
+		{"priced from the product", form.OrderItem{ProductId: drinkId, Quantity: 2}, constant.VatInclusive, 100, 6.54, false},
+		{"discount off the line", form.OrderItem{ProductId: drinkId, Quantity: 2, Discount: 10}, constant.VatInclusive, 90, 5.89, false},
+		{"client price within tolerance", form.OrderItem{ProductId: drinkId, Quantity: 2, Price: 100.005}, constant.VatInclusive, 100, 6.54, false},
+		{"exclusive VAT on top", form.OrderItem{ProductId: drinkId, Quantity: 2}, constant.VatExclusive, 107, 7, false},
+		{"zero rated", form.OrderItem{ProductId: milkId, Quantity: 1}, constant.VatExclusive, 40, 0, false},
+		{"client price off", form.OrderItem{ProductId: drinkId, Quantity: 2, Price: 95}, constant.VatInclusive, 0, 0, true},
+		{"discount over the line", form.OrderItem{ProductId: drinkId, Quantity: 2, Discount: 100.5}, constant.VatInclusive, 0, 0, true},
+		{"negative discount", form.OrderItem{ProductId: drinkId, Quantity: 2, Discount: -1}, constant.VatInclusive, 0, 0, true},
+		{"no quantity", form.OrderItem{ProductId: drinkId}, constant.VatInclusive, 0, 0, true},
+		{"unknown product", form.OrderItem{ProductId: primitive.NewObjectID().Hex(), Quantity: 1}, constant.VatInclusive, 0, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := test.item
+			err := calculateOrderItem(newStubProduct(), &item, test.vatMode)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("item = %+v, want an error", item)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Price != test.wantPrice || item.VatAmount != test.wantVat {
+				t.Errorf("price %.2f vat %.2f, want %.2f %.2f", item.Price, item.VatAmount, test.wantPrice, test.wantVat)
+			}
+		})
+	}
+}
+
+func TestCalculateOrder(t *testing.T) {
+	getItems := func() []form.OrderItem {
+		return []form.OrderItem{{ProductId: drinkId, Quantity: 2}, {ProductId: snackId, Quantity: 1}}
+	}
+	tenPercent := []model.Promotion{{Name: "10%", Type: constant.PromotionPercent, Value: 10}}
+	tests := []struct {
+		name         string
+		request      form.Order
+		promotions   []model.Promotion
+		wantTotal    float64
+		wantDiscount float64
+		wantVat      float64
+		wantChange   float64
+		wantErr      bool
+	}{
+		{
+			name:       "promotion shared across the lines",
+			request:    form.Order{Items: getItems(), Type: constant.CASH, Amount: 120},
+			promotions: tenPercent,
+			wantTotal:  117, wantDiscount: 13, wantVat: 7.66, wantChange: 3,
+		},
+		{
+			name:      "split tenders",
+			request:   form.Order{Items: getItems(), Payments: []form.Payment{{Type: constant.PROMPTPAY, Amount: 100}, {Type: constant.CASH, Amount: 50}}},
+			wantTotal: 130, wantVat: 8.5, wantChange: 20,
+		},
+		{
+			name:       "held order leaves promotions and payment to pay time",
+			request:    form.Order{Items: getItems(), Hold: true, Type: constant.CASH, Amount: 200},
+			promotions: tenPercent,
+			wantTotal:  130, wantVat: 8.5,
+		},
+		{
+			name:       "client total off",
+			request:    form.Order{Items: getItems(), Total: 130, Type: constant.CASH, Amount: 130},
+			promotions: tenPercent,
+			wantErr:    true,
+		},
+		{
+			name:    "short payment",
+			request: form.Order{Items: getItems(), Type: constant.CASH, Amount: 100},
+			wantErr: true,
+		},
+		{
+			name:    "change from a transfer",
+			request: form.Order{Items: getItems(), Type: constant.PROMPTPAY, Amount: 150},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := test.request
+			err := calculateOrder(newStubProduct(), &stubPromotion{promotions: test.promotions}, &request)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("order total %.2f, want an error", request.Total)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if request.Total != test.wantTotal || request.Discount != test.wantDiscount || request.VatAmount != test.wantVat || request.Change != test.wantChange {
+				t.Errorf("total %.2f discount %.2f vat %.2f change %.2f, want %.2f %.2f %.2f %.2f",
+					request.Total, request.Discount, request.VatAmount, request.Change,
+					test.wantTotal, test.wantDiscount, test.wantVat, test.wantChange)
+			}
+			if request.GrossTotal != 130 {
+				t.Errorf("gross total %.2f, want 130", request.GrossTotal)
+			}
+			if request.Hold && (request.Payments != nil || len(request.Promotions) != 0 || request.Amount != 0) {
+				t.Errorf("held order payments %v promotions %v amount %.2f, want none", request.Payments, request.Promotions, request.Amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// getTenders reads the single amount and type shorthand as one tender
+func getTenders(payments []form.Payment, amount float64, paymentType string) []form.Payment {
+	if len(payments) > 0 {
+		tenders := make([]form.Payment, len(payments))
+		copy(tenders, payments)
+		return tenders
+	}
+	if paymentType == "" {
+		return nil
+	}
+	return []form.Payment{{Type: paymentType, Amount: amount}}
+}
+
+// calculateTenders checks the tenders cover the total, only cash may go over it and give change,
+// which is taken from the last cash tenders
+func calculateTenders(total float64, payments []form.Payment, change *float64) (float64, error) {
+	if len(payments) == 0 {
+		return 0, errors.New("payments is required")
+	}
+	paid := 0.0
+	cash := 0.0
+	for _, payment := range payments {
+		if payment.Amount <= 0 {
+			return 0, fmt.Errorf("amount of %s must be greater than zero", payment.Type)
+		}
+		paid += payment.Amount
+		if payment.Type == constant.CASH {
+			cash += payment.Amount
+		}
+	}
+	paid = utils.RoundPrice(paid)
+	if paid < total {
+		return 0, errors.New("amount is less than total")
+	}
+	calculated := utils.RoundPrice(paid - total)
+	if calculated > utils.RoundPrice(cash) {
+		return 0, errors.New("only cash can be more than total")
+	}
+	if *change != 0 && math.Abs(*change-calculated) > priceTolerance {
+		return 0, fmt.Errorf("change does not match, expected %.2f", calculated)
+	}
+	*change = calculated
+
+	remaining := calculated
+	for index := len(payments) - 1; index >= 0 && remaining > 0; index-- {
+		if payments[index].Type != constant.CASH {
+			continue
+		}
+		payments[index].Change = math.Min(payments[index].Amount, remaining)
+		remaining = utils.RoundPrice(remaining - payments[index].Change)
+	}
+	return paid, nil
+}
+
+// getPaymentType is the type shared by every tender, SPLIT when they differ
+func getPaymentType(payments []form.Payment) string {
+	paymentType := ""
+	for _, payment := range payments {
+		if paymentType != "" && paymentType != payment.Type {
+			return constant.SPLIT
+		}
+		paymentType = payment.Type
+	}
+	return paymentType
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	"os"
+)
+
+// getVatMode tells whether product prices already include VAT, from VAT_MODE
+func getVatMode() string {
+	if os.Getenv("VAT_MODE") == constant.VatExclusive {
+		return constant.VatExclusive
+	}
+	return constant.VatInclusive
+}
+
+// calculateItemTax works out the VAT of a priced item, exclusive VAT is added on top of the item price
+func calculateItemTax(item *form.OrderItem, taxClass string, vatMode string) {
+	item.TaxClass = taxClass
+	item.VatRate = 0
+	item.VatAmount = 0
+	if taxClass != constant.TaxVat {
+		return
+	}
+	item.VatRate = utils.GetVatRate()
+	if vatMode == constant.VatExclusive {
+		item.VatAmount = utils.RoundPrice(item.Price * item.VatRate / 100)
+		item.Price = utils.RoundPrice(item.Price + item.VatAmount)
+		return
+	}
+	item.VatAmount = utils.RoundPrice(item.Price * item.VatRate / (100 + item.VatRate))
+}
+
+// calculateOrderTax sums the item taxes on the order by tax class
+func calculateOrderTax(request *form.Order) {
+	request.VatableAmount = 0
+	request.ZeroRatedAmount = 0
+	request.ExemptAmount = 0
+	request.VatAmount = 0
+	for _, item := range request.Items {
+		switch item.TaxClass {
+		case constant.TaxZero:
+			request.ZeroRatedAmount += item.Price
+		case constant.TaxExempt:
+			request.ExemptAmount += item.Price
+		default:
+			request.VatableAmount += item.Price - item.VatAmount
+			request.VatAmount += item.VatAmount
+		}
+	}
+	request.VatableAmount = utils.RoundPrice(request.VatableAmount)
+	request.ZeroRatedAmount = utils.RoundPrice(request.ZeroRatedAmount)
+	request.ExemptAmount = utils.RoundPrice(request.ExemptAmount)
+	request.VatAmount = utils.RoundPrice(request.VatAmount)
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/order/form"
+	"testing"
+)
+
+func TestCalculateItemTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     float64
+		taxClass  string
+		vatMode   string
+		wantPrice float64
+		wantRate  float64
+		wantVat   float64
+	}{
+		{"inclusive", 107, constant.TaxVat, constant.VatInclusive, 107, 7, 7},
+		{"inclusive rounds the extracted VAT", 10, constant.TaxVat, constant.VatInclusive, 10, 7, 0.65},
+		{"exclusive adds VAT on top", 100, constant.TaxVat, constant.VatExclusive, 107, 7, 7},
+		{"exclusive rounds the added VAT", 9.99, constant.TaxVat, constant.VatExclusive, 10.69, 7, 0.7},
+		{"zero rated", 50, constant.TaxZero, constant.VatInclusive, 50, 0, 0},
+		{"exempt is not added to", 50, constant.TaxExempt, constant.VatExclusive, 50, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := form.OrderItem{Price: test.price, VatRate: 10, VatAmount: 10}
+			calculateItemTax(&item, test.taxClass, test.vatMode)
+			if item.TaxClass != test.taxClass || item.Price != test.wantPrice || item.VatRate != test.wantRate || item.VatAmount != test.wantVat {
+				t.Errorf("item = %s price %.2f rate %.2f vat %.2f, want %s price %.2f rate %.2f vat %.2f",
+					item.TaxClass, item.Price, item.VatRate, item.VatAmount,
+					test.taxClass, test.wantPrice, test.wantRate, test.wantVat)
+			}
+		})
+	}
+}
+
+func TestCalculateOrderTax(t *testing.T) {
+	request := form.Order{Items: []form.OrderItem{
+		{Price: 107, TaxClass: constant.TaxVat, VatAmount: 7},
+		{Price: 10, TaxClass: constant.TaxVat, VatAmount: 0.65},
+		{Price: 50, TaxClass: constant.TaxZero},
+		{Price: 20, TaxClass: constant.TaxExempt},
+	}}
+	calculateOrderTax(&request)
+	if request.VatableAmount != 109.35 || request.VatAmount != 7.65 || request.ZeroRatedAmount != 50 || request.ExemptAmount != 20 {
+		t.Errorf("vatable %.2f vat %.2f zero rated %.2f exempt %.2f, want 109.35 7.65 50 20",
+			request.VatableAmount, request.VatAmount, request.ZeroRatedAmount, request.ExemptAmount)
+	}
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/order/form"
+	repository2 "devper/app/featues/product/repository"
+	"fmt"
+	"os"
+)
+
+func allowOversell() bool {
+	return os.Getenv("OVERSELL_POLICY") == constant.OversellAllow
+}
+
+// checkStock rejects items that would drive a product below zero unless overselling is allowed
+func checkStock(productEntity repository2.IProduct, items []form.OrderItem) error {
+	if allowOversell() {
+		return nil
+	}
+	requested := map[string]int{}
+	for _, item := range items {
+		requested[item.ProductId] += item.Quantity
+	}
+	for _, item := range items {
+		quantity, ok := requested[item.ProductId]
+		if !ok {
+			continue
+		}
+		delete(requested, item.ProductId)
+		product, err := productEntity.GetProductById(item.ProductId)
+		if err != nil {
+			return fmt.Errorf("product %s not found", item.ProductId)
+		}
+		if product.Quantity < quantity {
+			return fmt.Errorf("%w: %s available %d, requested %d", repository2.ErrInsufficientStock, product.Name, product.Quantity, quantity)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+func ConfirmPaymentById(orderEntity repository.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		paymentId := ctx.Param("paymentId")
+		request := form.ConfirmPayment{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		payment, err := orderEntity.GetPaymentById(paymentId)
+		if err != nil || payment.OrderId.Hex() != orderId {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "payment not found in order"})
+			return
+		}
+		result, err := orderEntity.ConfirmPaymentById(paymentId, request)
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "payment is " + payment.Status})
+			return
+		}
+		if err == repository.ErrOrderNotPayable {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -1,36 +1,102 @@
 package usecase
 
 import (
+	"devper/app/core/constant"
 	"devper/app/core/utils"
+	repository3 "devper/app/featues/counter/repository"
+	repository5 "devper/app/featues/customer/repository"
+	model2 "devper/app/featues/loyalty/model"
+	repository6 "devper/app/featues/loyalty/repository"
 	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
 	"devper/app/featues/order/repository"
 	repository2 "devper/app/featues/product/repository"
+	repository4 "devper/app/featues/promotion/repository"
+	repository7 "devper/app/featues/shift/repository"
+	"devper/db"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
 )
 
-func CreateOrder(orderEntity repository.IOrder, productEntity repository2.IProduct) gin.HandlerFunc {
+func CreateOrder(transaction db.ITransaction, orderEntity repository.IOrder, productEntity repository2.IProduct, counterEntity repository3.ICounter, promotionEntity repository4.IPromotion, customerEntity repository5.ICustomer, pointEntity repository6.IPoint, shiftEntity repository7.IShift) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		request := form.Order{}
 		if err := ctx.ShouldBind(&request); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		totalCost := 0.0
-		for index, item := range request.Items {
-			request.Items[index].CostPrice = productEntity.GetTotalCostPrice(item.ProductId, item.Quantity)
-			totalCost += request.Items[index].CostPrice
-		}
-		request.TotalCost = totalCost
-
-		result, err := orderEntity.CreateOrder(request)
+		request.CreatedBy = ctx.GetString("UserId")
+		request.ShiftId = getShiftId(shiftEntity, request.CreatedBy)
+		customer, err := getCustomer(customerEntity, request.CustomerId, request.MemberPhone)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if customer != nil {
+			request.CustomerId = customer.Id.Hex()
+		}
+		if err := calculateOrder(productEntity, promotionEntity, &request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rule := model2.NewRule()
+		if !request.Hold {
+			request.PointsEarned, request.PointsRedeemed, err = getOrderPoints(rule, customer, request.Items, request.Payments)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := checkStock(productEntity, request.Items); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		request.Id = primitive.NewObjectID()
 
-		for _, item := range request.Items {
-			_, _ = productEntity.RemoveQuantityById(item.ProductId, item.Quantity)
+		var result *model.Order
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			orderNumber, branch, err := nextOrderNumber(sessionCtx, counterEntity)
+			if err != nil {
+				return err
+			}
+			request.OrderNumber = orderNumber
+			request.Branch = branch
+			movement := getOrderMovement(constant.StockSale, request.Id, request.OrderNumber, request.CreatedBy)
+			totalCost := 0.0
+			for index := range request.Items {
+				err := deductOrderItem(sessionCtx, productEntity, &request.Items[index], movement)
+				if err != nil {
+					return err
+				}
+				totalCost += request.Items[index].CostPrice
+			}
+			request.TotalCost = totalCost
+			if err := usePromotions(sessionCtx, promotionEntity, request.Promotions); err != nil {
+				return err
+			}
+			order, err := orderEntity.CreateOrder(sessionCtx, request)
+			if err != nil {
+				return err
+			}
+			if customer != nil {
+				err = postOrderPoints(sessionCtx, pointEntity, rule, customer.Id, order.Id, request.Items, request.Payments, request.PointsEarned, request.PointsRedeemed, request.CreatedBy)
+				if err != nil {
+					return err
+				}
+			}
+			result = order
+			return nil
+		})
+		if errors.Is(err, repository2.ErrInsufficientStock) || errors.Is(err, repository4.ErrPromotionUsedUp) || errors.Is(err, repository6.ErrInsufficientPoints) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
 		if request.Message != "" {
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	repository2 "devper/app/featues/counter/repository"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	"devper/db"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"os"
+	"time"
+)
+
+// getTaxInvoiceNumber formats the yearly running number, e.g. INV2026-000042
+func getTaxInvoiceNumber(year int, seq int64) string {
+	prefix := os.Getenv("TAX_INVOICE_PREFIX")
+	if prefix == "" {
+		prefix = "INV"
+	}
+	return fmt.Sprintf("%s%d-%06d", prefix, year, seq)
+}
+
+// CreateTaxInvoice issues the order's full tax invoice once, asking again returns the invoice already issued
+func CreateTaxInvoice(transaction db.ITransaction, orderEntity repository.IOrder, counterEntity repository2.ICounter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		request := form.TaxInvoice{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CreatedBy = ctx.GetString("UserId")
+		if request.BuyerBranch == "" {
+			request.BuyerBranch = "00000"
+		}
+		order, err := orderEntity.GetOrderById(orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if order.Status == constant.OPEN || order.Status == constant.VOIDED || order.Status == constant.REFUNDED {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "order is " + order.Status})
+			return
+		}
+
+		location, _ := time.LoadLocation("Asia/Bangkok")
+		year := time.Now().In(location).Year()
+		var result *model.TaxInvoice
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			invoice, err := orderEntity.GetTaxInvoiceByOrderId(sessionCtx, orderId)
+			if err == nil {
+				result = invoice
+				return nil
+			}
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				return err
+			}
+			seq, err := counterEntity.NextSequence(sessionCtx, fmt.Sprintf("tax-invoice-%d", year))
+			if err != nil {
+				return err
+			}
+			invoice, err = orderEntity.CreateTaxInvoice(sessionCtx, model.TaxInvoice{
+				OrderId:         order.Id,
+				Number:          getTaxInvoiceNumber(year, seq),
+				BuyerName:       request.BuyerName,
+				BuyerTaxId:      request.BuyerTaxId,
+				BuyerBranch:     request.BuyerBranch,
+				BuyerAddress:    request.BuyerAddress,
+				VatableAmount:   order.VatableAmount,
+				ZeroRatedAmount: order.ZeroRatedAmount,
+				ExemptAmount:    order.ExemptAmount,
+				VatAmount:       order.VatAmount,
+				Total:           order.Total,
+				CreatedBy:       request.CreatedBy,
+			})
+			if err != nil {
+				return err
+			}
+			result = invoice
+			return nil
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
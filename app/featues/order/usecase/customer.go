@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"devper/app/featues/customer/model"
+	repository5 "devper/app/featues/customer/repository"
+	"fmt"
+)
+
+// getCustomer finds the customer attached to an order by id or by member phone, nil when there is none
+func getCustomer(customerEntity repository5.ICustomer, customerId string, memberPhone string) (*model.Customer, error) {
+	if customerId != "" {
+		customer, err := customerEntity.GetCustomerById(customerId)
+		if err != nil {
+			return nil, fmt.Errorf("customer %s not found", customerId)
+		}
+		return customer, nil
+	}
+	if memberPhone != "" {
+		customer, err := customerEntity.GetCustomerByPhone(memberPhone)
+		if err != nil {
+			return nil, fmt.Errorf("member %s not found", memberPhone)
+		}
+		return customer, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	repository2 "devper/app/featues/product/repository"
+	"devper/db"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+func DeleteOrderItemById(transaction db.ITransaction, orderEntity repository.IOrder, productEntity repository2.IProduct) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		itemId := ctx.Param("itemId")
+		order, err := getOpenOrder(orderEntity, orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var result *model.Order
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			item, err := orderEntity.RemoveOrderItemById(sessionCtx, itemId)
+			if err != nil {
+				return err
+			}
+			if item.OrderId.Hex() != orderId {
+				return errors.New("item not found in order")
+			}
+			err = returnOrderItem(sessionCtx, productEntity, item.ProductId.Hex(), item.Quantity, item.Lots, getOrderMovement(constant.StockVoid, order.Id, order.OrderNumber, ctx.GetString("UserId")))
+			if err != nil {
+				return err
+			}
+			order, err := orderEntity.UpdateTotalOrderById(sessionCtx, orderId)
+			if err != nil {
+				return err
+			}
+			if order.Status != constant.OPEN {
+				return errOrderNotOpen
+			}
+			result = order
+			return nil
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"devper/app/core/utils"
 	"devper/app/featues/order/repository"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -9,7 +10,9 @@ import (
 func GetOrderById(orderEntity repository.IOrder) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		orderId := ctx.Param("orderId")
-		result, err := orderEntity.GetOrderDetailById(orderId)
+		c, cancel := utils.InitContext()
+		defer cancel()
+		result, err := orderEntity.GetOrderDetailById(c, orderId)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/order/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetOrderByNumber looks the number up in this till's branch unless another branch is asked for
+func GetOrderByNumber(orderEntity repository.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderNumber := ctx.Param("orderNumber")
+		branch := ctx.DefaultQuery("branch", utils.GetBranchCode())
+		result, err := orderEntity.GetOrderDetailByNumber(branch, orderNumber)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
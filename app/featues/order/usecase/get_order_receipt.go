@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/receipt"
+	"devper/app/featues/order/repository"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func getReceiptConfig(request form.GetReceipt) receipt.Config {
+	config := receipt.NewConfig()
+	if request.Width == 58 {
+		config.Width = receipt.Width58mm
+	}
+	if request.Lang != "" {
+		config.Lang = request.Lang
+	}
+	return config
+}
+
+func GetOrderReceipt(orderEntity repository.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetReceipt{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		orderId := ctx.Param("orderId")
+		c, cancel := utils.InitContext()
+		defer cancel()
+		order, err := orderEntity.GetOrderDetailById(c, orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		config := getReceiptConfig(request)
+
+		switch request.Format {
+		case "html":
+			result, err := receipt.HTML(order, config)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.Data(http.StatusOK, "text/html; charset=utf-8", result)
+		case "pdf":
+			result, err := receipt.PDF(order, config)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.Header("Content-Disposition", fmt.Sprintf("inline; filename=receipt-%s.pdf", orderId))
+			ctx.Data(http.StatusOK, "application/pdf", result)
+		case "escpos":
+			ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=receipt-%s.bin", orderId))
+			ctx.Data(http.StatusOK, "application/octet-stream", receipt.ESCPOS(order, config, request.Code))
+		default:
+			ctx.String(http.StatusOK, receipt.Text(order, config))
+		}
+	}
+}
@@ -5,21 +5,23 @@ import (
 	"devper/app/featues/order/repository"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
-func GetOrdersRange(orderEntity repository.IOrder) gin.HandlerFunc {
+func GetOrders(orderEntity repository.IOrder) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		request := form.GetOrderRange{}
+		request := form.GetOrders{}
 		if err := ctx.ShouldBindQuery(&request); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		result, err := orderEntity.GetOrderRange(request)
+		result, total, err := orderEntity.GetOrders(request)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
+		ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
 		ctx.JSON(http.StatusOK, result)
 	}
 }
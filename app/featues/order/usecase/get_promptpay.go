@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/promptpay"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/repository"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+)
+
+// renderPromptPay answers with the QR payload as JSON or as a PNG image when format=png
+func renderPromptPay(ctx *gin.Context, amount float64, result gin.H) {
+	request := form.GetPromptPay{}
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payload, err := promptpay.Payload(os.Getenv("PROMPTPAY_ID"), amount)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Format == "png" {
+		size := request.Size
+		if size == 0 {
+			size = 256
+		}
+		image, err := promptpay.PNG(payload, size)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Data(http.StatusOK, "image/png", image)
+		return
+	}
+	result["payload"] = payload
+	result["amount"] = amount
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetPromptPay is the shop's static QR, the customer keys in the amount
+func GetPromptPay() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		renderPromptPay(ctx, 0, gin.H{})
+	}
+}
+
+// GetOrderPromptPay is the dynamic QR for the order's pending PromptPay payment
+func GetOrderPromptPay(orderEntity repository.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		payments, err := orderEntity.GetPaymentsByOrderId(orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, payment := range payments {
+			if payment.Type == constant.PROMPTPAY && payment.Status == constant.PENDING {
+				renderPromptPay(ctx, payment.Total, gin.H{"paymentId": payment.Id})
+				return
+			}
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errors.New("no pending promptpay payment").Error()})
+	}
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/order/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetTaxInvoiceByOrderId(orderEntity repository.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		c, cancel := utils.InitContext()
+		defer cancel()
+		result, err := orderEntity.GetTaxInvoiceByOrderId(c, orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
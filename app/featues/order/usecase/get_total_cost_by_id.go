@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/repository"
+	repository2 "devper/app/featues/product/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetTotalCostById previews the re-costed order without saving it
+func GetTotalCostById(orderEntity repository.IOrder, productEntity repository2.IProduct) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		request := form.UpdateTotalCost{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c, cancel := utils.InitContext()
+		defer cancel()
+		order, err := orderEntity.GetOrderDetailById(c, orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		costs, totalCost, err := getItemCosts(productEntity, order, request.Method)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for index := range order.Items {
+			order.Items[index].CostPrice = costs[index]
+		}
+		order.TotalCost = totalCost
+
+		ctx.JSON(http.StatusOK, order)
+	}
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetVatReport(orderEntity repository.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetVatReport{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := orderEntity.GetVatReport(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	model2 "devper/app/featues/customer/model"
+	"devper/app/featues/loyalty/model"
+	repository6 "devper/app/featues/loyalty/repository"
+	"devper/app/featues/order/form"
+	model3 "devper/app/featues/order/model"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+)
+
+// getOrderPoints works out the points the POINTS tenders redeem and the points the rest of the sale earns,
+// items in excluded categories earn nothing
+func getOrderPoints(rule model.Rule, customer *model2.Customer, items []form.OrderItem, payments []form.Payment) (int, int, error) {
+	redeemAmount := getRedeemAmount(payments)
+	if customer == nil {
+		if redeemAmount > 0 {
+			return 0, 0, errors.New("paying by points needs a member")
+		}
+		return 0, 0, nil
+	}
+	redeem := 0
+	if redeemAmount > 0 {
+		points, err := rule.GetRedeemPoints(redeemAmount)
+		if err != nil {
+			return 0, 0, err
+		}
+		redeem = points
+	}
+	return rule.GetEarnPoints(getEarnAmount(rule, items, payments)), redeem, nil
+}
+
+func getRedeemAmount(payments []form.Payment) float64 {
+	amount := 0.0
+	for _, payment := range payments {
+		if payment.Type == constant.POINTS {
+			amount += payment.Amount
+		}
+	}
+	return utils.RoundPrice(amount)
+}
+
+// getEarnAmount is the part of the sale that earns points, what was paid by points earns nothing
+func getEarnAmount(rule model.Rule, items []form.OrderItem, payments []form.Payment) float64 {
+	amount := 0.0
+	for _, item := range items {
+		if !rule.IsExcluded(item.Category) {
+			amount += item.Price
+		}
+	}
+	return utils.RoundPrice(math.Max(amount-getRedeemAmount(payments), 0))
+}
+
+// postOrderPoints redeems and then earns the points of a paid order on the customer ledger
+func postOrderPoints(
+	ctx context.Context,
+	pointEntity repository6.IPoint,
+	rule model.Rule,
+	customerId primitive.ObjectID,
+	orderId primitive.ObjectID,
+	items []form.OrderItem,
+	payments []form.Payment,
+	earn int,
+	redeem int,
+	createdBy string,
+) error {
+	if redeem > 0 {
+		_, err := pointEntity.AddPoints(ctx, model.PointEntry{
+			CustomerId: customerId,
+			OrderId:    orderId,
+			Type:       constant.PointRedeem,
+			Points:     -redeem,
+			Amount:     getRedeemAmount(payments),
+			CreatedBy:  createdBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if earn > 0 {
+		_, err := pointEntity.AddPoints(ctx, model.PointEntry{
+			CustomerId: customerId,
+			OrderId:    orderId,
+			Type:       constant.PointEarn,
+			Points:     earn,
+			Amount:     getEarnAmount(rule, items, payments),
+			CreatedBy:  createdBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseOrderPoints takes back what the refunded part of an order earned and gives back the points of its
+// POINTS share, a void that pays the POINTS tender back gives back every point still redeemed
+func reverseOrderPoints(
+	ctx context.Context,
+	pointEntity repository6.IPoint,
+	order *model3.OrderDetail,
+	refund *model3.Refund,
+	status string,
+) error {
+	if order.CustomerId.IsZero() {
+		return nil
+	}
+	points, err := pointEntity.GetOrderPoints(ctx, order.Id)
+	if err != nil {
+		return err
+	}
+	refunded := refund.Total
+	for _, item := range order.Refunds {
+		refunded += item.Total
+	}
+	keep := 0
+	if status != constant.VOIDED && order.Total > 0 {
+		remaining := math.Max(order.Total-refunded, 0)
+		keep = int(math.Floor(float64(order.PointsEarned) * remaining / order.Total))
+	}
+	if reverse := points.Earned - keep; reverse > 0 {
+		_, err = pointEntity.AddPoints(ctx, model.PointEntry{
+			CustomerId: order.CustomerId,
+			OrderId:    order.Id,
+			RefundId:   refund.Id,
+			Type:       constant.PointReverse,
+			Points:     -reverse,
+			Amount:     refund.Total,
+			Note:       refund.Reason,
+			CreatedBy:  refund.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	pointsShare := 0.0
+	for _, tender := range refund.Tenders {
+		if tender.Type == constant.POINTS {
+			pointsShare += tender.Amount
+		}
+	}
+	giveBack := 0
+	if pointsShare > 0 && status == constant.VOIDED {
+		giveBack = points.Redeemed
+	} else if pointsShare > 0 {
+		rule := model.NewRule()
+		if rule.PointValue > 0 {
+			giveBack = int(math.Min(math.Floor(pointsShare/rule.PointValue+0.001), float64(points.Redeemed)))
+		}
+	}
+	if giveBack > 0 {
+		_, err = pointEntity.AddPoints(ctx, model.PointEntry{
+			CustomerId: order.CustomerId,
+			OrderId:    order.Id,
+			RefundId:   refund.Id,
+			Type:       constant.PointReturn,
+			Points:     giveBack,
+			Note:       refund.Reason,
+			CreatedBy:  refund.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	model2 "devper/app/featues/customer/model"
+	"devper/app/featues/loyalty/model"
+	"devper/app/featues/order/form"
+	"errors"
+	"testing"
+)
+
+func TestGetOrderPoints(t *testing.T) {
+	rule := model.Rule{BahtPerPoint: 25, PointValue: 1, ExcludedCategories: []string{"TOBACCO"}}
+	member := &model2.Customer{Name: "Member"}
+	items := []form.OrderItem{{Price: 110, Category: "DRINK"}, {Price: 50, Category: "tobacco"}}
+	tests := []struct {
+		name       string
+		rule       model.Rule
+		customer   *model2.Customer
+		payments   []form.Payment
+		wantEarn   int
+		wantRedeem int
+		wantErr    error
+	}{
+		{"walk-in earns nothing", rule, nil, []form.Payment{{Type: constant.CASH, Amount: 160}}, 0, 0, nil},
+		{"excluded category earns nothing", rule, member, []form.Payment{{Type: constant.CASH, Amount: 160}}, 4, 0, nil},
+		{"points share earns nothing", rule, member, []form.Payment{{Type: constant.POINTS, Amount: 30}, {Type: constant.CASH, Amount: 130}}, 3, 30, nil},
+		{"points pay the whole sale", rule, member, []form.Payment{{Type: constant.POINTS, Amount: 160}}, 0, 160, nil},
+		{"accrual turned off", model.Rule{PointValue: 1}, member, []form.Payment{{Type: constant.CASH, Amount: 160}}, 0, 0, nil},
+		{"points worth two baht", model.Rule{BahtPerPoint: 25, PointValue: 2}, member, []form.Payment{{Type: constant.POINTS, Amount: 60}, {Type: constant.CASH, Amount: 100}}, 4, 30, nil},
+		{"part of a point", model.Rule{BahtPerPoint: 25, PointValue: 2}, member, []form.Payment{{Type: constant.POINTS, Amount: 25}, {Type: constant.CASH, Amount: 135}}, 0, 0, model.ErrPointsInvalid},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			earn, redeem, err := getOrderPoints(test.rule, test.customer, items, test.payments)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("err = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if earn != test.wantEarn || redeem != test.wantRedeem {
+				t.Errorf("earn %d redeem %d, want %d %d", earn, redeem, test.wantEarn, test.wantRedeem)
+			}
+		})
+	}
+}
+
+func TestGetOrderPointsNeedsMemberToRedeem(t *testing.T) {
+	rule := model.Rule{BahtPerPoint: 25, PointValue: 1}
+	items := []form.OrderItem{{Price: 100}}
+	_, _, err := getOrderPoints(rule, nil, items, []form.Payment{{Type: constant.POINTS, Amount: 100}})
+	if err == nil {
+		t.Error("walk-in paid by points, want an error")
+	}
+}
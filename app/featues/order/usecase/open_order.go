@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	"errors"
+)
+
+var (
+	errOrderNotOpen   = errors.New("order is not open")
+	errItemNotInOrder = errors.New("item not found in order")
+)
+
+func getOpenOrder(orderEntity repository.IOrder, orderId string) (*model.Order, error) {
+	order, err := orderEntity.GetOrderById(orderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != constant.OPEN {
+		return nil, errOrderNotOpen
+	}
+	return order, nil
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"devper/app/core/utils"
+	repository2 "devper/app/featues/counter/repository"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	resetDay  = "DAY"
+	resetYear = "YEAR"
+
+	defaultOrderNumberFormat = "{YYYY}{MM}{DD}-{SEQ:4}"
+)
+
+var seqPattern = regexp.MustCompile(`\{SEQ(?::(\d+))?\}`)
+
+// getOrderNumberKey names the counter, one per branch and per day or year depending on ORDER_NUMBER_RESET
+func getOrderNumberKey(branch string, date time.Time) string {
+	period := date.Format("20060102")
+	if os.Getenv("ORDER_NUMBER_RESET") == resetYear {
+		period = date.Format("2006")
+	}
+	return "order-" + branch + "-" + period
+}
+
+// checkOrderNumberFormat makes sure a number cannot repeat, the format needs {SEQ} and the date tokens of the
+// period the counter resets on, a year for YEAR and the year, month and day for DAY
+func checkOrderNumberFormat(format string, reset string) error {
+	if !seqPattern.MatchString(format) {
+		return errors.New("ORDER_NUMBER_FORMAT has no {SEQ}")
+	}
+	if !strings.Contains(format, "{YYYY}") && !strings.Contains(format, "{YY}") {
+		return errors.New("ORDER_NUMBER_FORMAT has no {YYYY} or {YY}")
+	}
+	if reset != resetYear && (!strings.Contains(format, "{MM}") || !strings.Contains(format, "{DD}")) {
+		return errors.New("ORDER_NUMBER_FORMAT needs {MM} and {DD} when ORDER_NUMBER_RESET is DAY")
+	}
+	return nil
+}
+
+// getOrderNumberFormat is ORDER_NUMBER_FORMAT, or the default when it is unset or could repeat a number
+func getOrderNumberFormat() (string, error) {
+	format := os.Getenv("ORDER_NUMBER_FORMAT")
+	if format == "" {
+		return defaultOrderNumberFormat, nil
+	}
+	if err := checkOrderNumberFormat(format, os.Getenv("ORDER_NUMBER_RESET")); err != nil {
+		return defaultOrderNumberFormat, err
+	}
+	return format, nil
+}
+
+// CheckOrderNumberFormat warns at startup when ORDER_NUMBER_FORMAT is unusable and the default is used instead
+func CheckOrderNumberFormat() {
+	if _, err := getOrderNumberFormat(); err != nil {
+		logrus.Warnf("%v, order numbers use %s", err, defaultOrderNumberFormat)
+	}
+}
+
+// formatOrderNumber fills {BRANCH}, {YYYY}, {YY}, {MM}, {DD} and {SEQ:n} with n zero padded digits
+func formatOrderNumber(format string, branch string, date time.Time, seq int64) string {
+	number := strings.NewReplacer(
+		"{BRANCH}", branch,
+		"{YYYY}", date.Format("2006"),
+		"{YY}", date.Format("06"),
+		"{MM}", date.Format("01"),
+		"{DD}", date.Format("02"),
+	).Replace(format)
+	return seqPattern.ReplaceAllStringFunc(number, func(token string) string {
+		width, _ := strconv.Atoi(seqPattern.FindStringSubmatch(token)[1])
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+}
+
+// nextOrderNumber takes the next number from the counter within the order transaction, a failed or retried
+// checkout gives its number back so the sequence has no gaps
+func nextOrderNumber(ctx context.Context, counterEntity repository2.ICounter) (string, string, error) {
+	format, _ := getOrderNumberFormat()
+	branch := utils.GetBranchCode()
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	date := time.Now().In(location)
+	seq, err := counterEntity.NextSequence(ctx, getOrderNumberKey(branch, date))
+	if err != nil {
+		return "", "", err
+	}
+	return formatOrderNumber(format, branch, date, seq), branch, nil
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCheckOrderNumberFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		reset  string
+		valid  bool
+	}{
+		{"default", defaultOrderNumberFormat, resetDay, true},
+		{"branch and short year", "{BRANCH}-{YY}{MM}{DD}-{SEQ}", resetDay, true},
+		{"reset unset is daily", "{YYYY}-{SEQ:6}", "", false},
+		{"yearly", "{BRANCH}{YYYY}-{SEQ:6}", resetYear, true},
+		{"no sequence", "{YYYY}{MM}{DD}", resetDay, false},
+		{"no year", "{MM}{DD}-{SEQ:4}", resetDay, false},
+		{"daily without day", "{YYYY}{MM}-{SEQ:4}", resetDay, false},
+		{"yearly without year", "{BRANCH}-{SEQ:4}", resetYear, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkOrderNumberFormat(test.format, test.reset)
+			if (err == nil) != test.valid {
+				t.Errorf("checkOrderNumberFormat(%q, %q) = %v, want valid %v", test.format, test.reset, err, test.valid)
+			}
+		})
+	}
+}
+
+func TestGetOrderNumberFormatFallsBack(t *testing.T) {
+	_ = os.Setenv("ORDER_NUMBER_FORMAT", "{BRANCH}-{SEQ:4}")
+	defer os.Unsetenv("ORDER_NUMBER_FORMAT")
+	format, err := getOrderNumberFormat()
+	if err == nil || format != defaultOrderNumberFormat {
+		t.Errorf("format = %q, %v, want %q and an error", format, err, defaultOrderNumberFormat)
+	}
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/order/form"
+	"devper/app/featues/product/model"
+	repository2 "devper/app/featues/product/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// getOrderMovement references the order on the stock card
+func getOrderMovement(movementType string, orderId primitive.ObjectID, orderNumber string, createdBy string) model.StockMovement {
+	return model.StockMovement{
+		Type:      movementType,
+		RefType:   constant.RefOrder,
+		RefId:     orderId,
+		RefNumber: orderNumber,
+		CreatedBy: createdBy,
+	}
+}
+
+// deductOrderItem takes the item out of stock and its lots, freezing the lot cost on the item
+func deductOrderItem(sessionCtx mongo.SessionContext, productEntity repository2.IProduct, item *form.OrderItem, movement model.StockMovement) error {
+	lots, err := productEntity.AllocateLots(sessionCtx, item.ProductId, item.Quantity)
+	if err != nil {
+		return err
+	}
+	movement.Lots = lots
+	product, err := productEntity.RemoveQuantityById(sessionCtx, item.ProductId, item.Quantity, allowOversell(), movement)
+	if err != nil {
+		return err
+	}
+	item.Lots = lots
+	item.CostPrice = model.GetLotsCostPrice(lots, item.Quantity, product.CostPrice)
+	return nil
+}
+
+// returnOrderItem puts the units back in stock and into the lots they came from
+func returnOrderItem(sessionCtx mongo.SessionContext, productEntity repository2.IProduct, productId string, quantity int, lots []model.LotAllocation, movement model.StockMovement) error {
+	movement.Lots = lots
+	_, err := productEntity.AddQuantityById(sessionCtx, productId, quantity, movement)
+	if err != nil {
+		return err
+	}
+	return productEntity.ReturnLots(sessionCtx, lots)
+}
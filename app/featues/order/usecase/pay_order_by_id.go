@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	repository5 "devper/app/featues/customer/repository"
+	model2 "devper/app/featues/loyalty/model"
+	repository6 "devper/app/featues/loyalty/repository"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	repository4 "devper/app/featues/promotion/repository"
+	repository7 "devper/app/featues/shift/repository"
+	"devper/db"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"math"
+	"net/http"
+)
+
+func PayOrderById(transaction db.ITransaction, orderEntity repository.IOrder, promotionEntity repository4.IPromotion, customerEntity repository5.ICustomer, pointEntity repository6.IPoint, shiftEntity repository7.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		request := form.PayOrder{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		request.ShiftId = getShiftId(shiftEntity, request.UpdatedBy)
+		order, err := getOpenOrder(orderEntity, orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		customer, err := getCustomer(customerEntity, request.CustomerId, request.MemberPhone)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if customer != nil {
+			request.CustomerId = customer.Id.Hex()
+		} else if !order.CustomerId.IsZero() {
+			customer, err = getCustomer(customerEntity, order.CustomerId.Hex(), "")
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		promotions, err := getPromotions(promotionEntity, request.Coupon)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rule := model2.NewRule()
+
+		var result *model.Order
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			// the held items are priced from what the order holds in this transaction, and the request is
+			// copied so a retry starts over from what was asked
+			pay := request
+			held, err := orderEntity.GetOrderDetailById(sessionCtx, orderId)
+			if err != nil {
+				return err
+			}
+			if held.Status != constant.OPEN {
+				return errOrderNotOpen
+			}
+			items := getHeldItems(held.Items, held.VatMode)
+			pay.Promotions, err = applyPromotions(items, promotions, pay.Coupon, held.VatMode)
+			if err != nil {
+				return err
+			}
+			total := held.Total
+			if len(pay.Promotions) > 0 {
+				total = 0
+				for _, item := range items {
+					total += item.Price
+				}
+				total = utils.RoundPrice(total)
+			}
+			pay.Payments = getTenders(pay.Payments, pay.Amount, pay.Type)
+			pay.Amount, err = calculateTenders(total, pay.Payments, &pay.Change)
+			if err != nil {
+				return err
+			}
+			pay.Type = getPaymentType(pay.Payments)
+			pay.PointsEarned, pay.PointsRedeemed, err = getOrderPoints(rule, customer, items, pay.Payments)
+			if err != nil {
+				return err
+			}
+
+			if len(pay.Promotions) > 0 {
+				for index, detail := range held.Items {
+					items[index].UpdatedBy = pay.UpdatedBy
+					_, err := orderEntity.UpdateOrderItemPriceById(sessionCtx, detail.Id.Hex(), items[index])
+					if err != nil {
+						return err
+					}
+				}
+				if err := usePromotions(sessionCtx, promotionEntity, pay.Promotions); err != nil {
+					return err
+				}
+				if _, err := orderEntity.UpdateTotalOrderById(sessionCtx, orderId); err != nil {
+					return err
+				}
+			}
+			paid, err := orderEntity.PayOrderById(sessionCtx, orderId, pay)
+			if err == mongo.ErrNoDocuments {
+				return errOrderNotOpen
+			}
+			if err != nil {
+				return err
+			}
+			if math.Abs(paid.Total-total) > priceTolerance {
+				return errors.New("order total changed, please retry")
+			}
+			if customer != nil {
+				err = postOrderPoints(sessionCtx, pointEntity, rule, customer.Id, paid.Id, items, pay.Payments, pay.PointsEarned, pay.PointsRedeemed, pay.UpdatedBy)
+				if err != nil {
+					return err
+				}
+			}
+			result = paid
+			return nil
+		})
+		if errors.Is(err, repository4.ErrPromotionUsedUp) || errors.Is(err, repository6.ErrInsufficientPoints) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
+
+// getHeldItems turns the stored items of a held order back into priced items the promotions can work on
+func getHeldItems(details []model.OrderItemDetail, vatMode string) []form.OrderItem {
+	items := make([]form.OrderItem, len(details))
+	for index, detail := range details {
+		gross := detail.Price + detail.Discount
+		if vatMode == constant.VatExclusive {
+			gross -= detail.VatAmount
+		}
+		taxClass := detail.TaxClass
+		if taxClass == "" {
+			taxClass = constant.TaxVat
+		}
+		items[index] = form.OrderItem{
+			ProductId:         detail.ProductId.Hex(),
+			Quantity:          detail.Quantity,
+			Price:             detail.Price,
+			CostPrice:         detail.CostPrice,
+			Discount:          detail.Discount,
+			Lots:              detail.Lots,
+			TaxClass:          taxClass,
+			VatRate:           detail.VatRate,
+			VatAmount:         detail.VatAmount,
+			Category:          detail.Product.Category,
+			Gross:             utils.RoundPrice(gross),
+			PromotionDiscount: detail.PromotionDiscount,
+		}
+	}
+	return items
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/promptpay"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"math"
+	"net/http"
+	"os"
+)
+
+// PromptPayWebhook confirms a payment from the bank's callback, the raw body must be signed with
+// PROMPTPAY_WEBHOOK_SECRET in the X-Signature header, a repeated callback returns the paid payment
+func PromptPayWebhook(orderEntity repository.IOrder) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		body, err := ctx.GetRawData()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !promptpay.VerifySignature(os.Getenv("PROMPTPAY_WEBHOOK_SECRET"), body, ctx.GetHeader("X-Signature")) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+			return
+		}
+		request := form.PromptPayWebhook{}
+		if err := binding.JSON.BindBody(body, &request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		payment, err := orderEntity.GetPaymentById(request.PaymentId)
+		if err != nil || payment.Type != constant.PROMPTPAY {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "payment not found"})
+			return
+		}
+		if payment.Status == constant.PAID {
+			ctx.JSON(http.StatusOK, payment)
+			return
+		}
+		if math.Abs(payment.Total-request.Amount) > priceTolerance {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "amount does not match"})
+			return
+		}
+		result, err := orderEntity.ConfirmPaymentById(request.PaymentId, form.ConfirmPayment{
+			Reference: request.Reference,
+			UpdatedBy: "webhook",
+		})
+		if err == repository.ErrOrderNotPayable {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
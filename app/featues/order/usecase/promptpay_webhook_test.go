@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"bytes"
+	"devper/app/core/constant"
+	"devper/app/core/promptpay"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+const webhookSecret = "webhook-secret"
+
+// stubOrder keeps the payments of one order in memory, any other IOrder method panics on the nil interface
+type stubOrder struct {
+	repository.IOrder
+	payments    map[string]*model.Payment
+	orderStatus string
+	confirmed   int
+}
+
+func (stub *stubOrder) GetPaymentById(id string) (*model.Payment, error) {
+	payment, ok := stub.payments[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	result := *payment
+	return &result, nil
+}
+
+func (stub *stubOrder) ConfirmPaymentById(id string, form form.ConfirmPayment) (*model.Payment, error) {
+	payment, ok := stub.payments[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	if stub.orderStatus != constant.OPEN && stub.orderStatus != constant.ACTIVE {
+		return nil, repository.ErrOrderNotPayable
+	}
+	stub.confirmed++
+	payment.Status = constant.PAID
+	payment.Reference = form.Reference
+	payment.UpdatedBy = form.UpdatedBy
+	result := *payment
+	return &result, nil
+}
+
+func newStubOrder() (*stubOrder, string) {
+	paymentId := primitive.NewObjectID()
+	return &stubOrder{
+		payments: map[string]*model.Payment{
+			paymentId.Hex(): {
+				Id:     paymentId,
+				Status: constant.PENDING,
+				Type:   constant.PROMPTPAY,
+				Total:  107,
+			},
+		},
+		orderStatus: constant.OPEN,
+	}, paymentId.Hex()
+}
+
+func postWebhook(stub *stubOrder, body []byte, signature string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/webhook", PromptPayWebhook(stub))
+	request := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		request.Header.Set("X-Signature", signature)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func getWebhookBody(t *testing.T, paymentId string, amount float64) []byte {
+	body, err := json.Marshal(form.PromptPayWebhook{PaymentId: paymentId, Reference: "BANK-REF-1", Amount: amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestPromptPayWebhook(t *testing.T) {
+	_ = os.Setenv("PROMPTPAY_WEBHOOK_SECRET", webhookSecret)
+	defer os.Unsetenv("PROMPTPAY_WEBHOOK_SECRET")
+
+	t.Run("confirms a signed callback", func(t *testing.T) {
+		stub, paymentId := newStubOrder()
+		body := getWebhookBody(t, paymentId, 107)
+		recorder := postWebhook(stub, body, promptpay.Sign(webhookSecret, body))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body.String())
+		}
+		payment := stub.payments[paymentId]
+		if payment.Status != constant.PAID || payment.Reference != "BANK-REF-1" || payment.UpdatedBy != "webhook" {
+			t.Errorf("payment = %+v, want paid with the bank reference", payment)
+		}
+	})
+
+	t.Run("repeated callback confirms once", func(t *testing.T) {
+		stub, paymentId := newStubOrder()
+		body := getWebhookBody(t, paymentId, 107)
+		signature := promptpay.Sign(webhookSecret, body)
+		postWebhook(stub, body, signature)
+		recorder := postWebhook(stub, body, signature)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body.String())
+		}
+		if stub.confirmed != 1 {
+			t.Errorf("confirmed %d times, want 1", stub.confirmed)
+		}
+	})
+
+	t.Run("rejects a bad signature", func(t *testing.T) {
+		stub, paymentId := newStubOrder()
+		body := getWebhookBody(t, paymentId, 107)
+		for _, signature := range []string{"", promptpay.Sign("other-secret", body)} {
+			recorder := postWebhook(stub, body, signature)
+			if recorder.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+			}
+		}
+		if stub.confirmed != 0 {
+			t.Errorf("confirmed %d times, want 0", stub.confirmed)
+		}
+	})
+
+	t.Run("rejects a different amount", func(t *testing.T) {
+		stub, paymentId := newStubOrder()
+		body := getWebhookBody(t, paymentId, 100)
+		recorder := postWebhook(stub, body, promptpay.Sign(webhookSecret, body))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+		}
+		if stub.confirmed != 0 {
+			t.Errorf("confirmed %d times, want 0", stub.confirmed)
+		}
+	})
+
+	t.Run("rejects an unknown payment", func(t *testing.T) {
+		stub, _ := newStubOrder()
+		body := getWebhookBody(t, primitive.NewObjectID().Hex(), 107)
+		recorder := postWebhook(stub, body, promptpay.Sign(webhookSecret, body))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("rejects a voided order", func(t *testing.T) {
+		stub, paymentId := newStubOrder()
+		stub.orderStatus = constant.VOIDED
+		body := getWebhookBody(t, paymentId, 107)
+		recorder := postWebhook(stub, body, promptpay.Sign(webhookSecret, body))
+		if recorder.Code != http.StatusConflict {
+			t.Errorf("status = %d, want %d", recorder.Code, http.StatusConflict)
+		}
+		if stub.payments[paymentId].Status != constant.PENDING {
+			t.Errorf("payment status = %s, want it left %s", stub.payments[paymentId].Status, constant.PENDING)
+		}
+	})
+
+	t.Run("rejects a cash payment", func(t *testing.T) {
+		stub, paymentId := newStubOrder()
+		stub.payments[paymentId].Type = constant.CASH
+		body := getWebhookBody(t, paymentId, 107)
+		recorder := postWebhook(stub, body, promptpay.Sign(webhookSecret, body))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+		}
+		if stub.confirmed != 0 {
+			t.Errorf("confirmed %d times, want 0", stub.confirmed)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	repository6 "devper/app/featues/loyalty/repository"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	repository2 "devper/app/featues/product/repository"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"math"
+)
+
+// refundOrder returns the requested units to stock, pays the refund back through the order tenders and
+// reverses the loyalty points, status is worked out from the remaining units when empty
+// order has to be read with sessionCtx, a concurrent void or refund then conflicts on the status update
+// and the retry sees the new status
+func refundOrder(
+	sessionCtx mongo.SessionContext,
+	orderEntity repository.IOrder,
+	productEntity repository2.IProduct,
+	pointEntity repository6.IPoint,
+	order *model.OrderDetail,
+	request form.Refund,
+	status string,
+) (*model.Refund, error) {
+	if order.Status == constant.VOIDED || order.Status == constant.REFUNDED {
+		return nil, errors.New("order already " + order.Status)
+	}
+	if order.Status == constant.OPEN {
+		return nil, errors.New("order is not paid")
+	}
+	orderItems := make([]model.OrderItemDetail, len(order.Items))
+	copy(orderItems, order.Items)
+	items := map[string]*model.OrderItemDetail{}
+	for index := range orderItems {
+		items[orderItems[index].Id.Hex()] = &orderItems[index]
+	}
+
+	shiftId, _ := primitive.ObjectIDFromHex(request.ShiftId)
+	refund := model.Refund{
+		OrderId:   order.Id,
+		Reason:    request.Reason,
+		Items:     []model.RefundItem{},
+		ShiftId:   shiftId,
+		CreatedBy: request.CreatedBy,
+	}
+	refund.Type = constant.REFUNDED
+	movementType := constant.StockReturn
+	if status == constant.VOIDED {
+		refund.Type = constant.VOIDED
+		movementType = constant.StockVoid
+	}
+	movement := getOrderMovement(movementType, order.Id, order.OrderNumber, request.CreatedBy)
+	movement.Note = request.Reason
+	for _, refundItem := range request.Items {
+		item, ok := items[refundItem.ItemId]
+		if !ok {
+			return nil, fmt.Errorf("item %s not found in order", refundItem.ItemId)
+		}
+		remaining := item.Quantity - item.RefundedQuantity
+		if refundItem.Quantity <= 0 || refundItem.Quantity > remaining {
+			return nil, fmt.Errorf("refund quantity of %s is invalid, remaining %d", item.Product.Name, remaining)
+		}
+		_, err := orderEntity.AddRefundedQuantityById(sessionCtx, refundItem.ItemId, refundItem.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		lots := item.GetRefundLots(refundItem.Quantity)
+		err = returnOrderItem(sessionCtx, productEntity, item.ProductId.Hex(), refundItem.Quantity, lots, movement)
+		if err != nil {
+			return nil, err
+		}
+
+		refunded := utils.RoundPrice(item.Price * float64(item.RefundedQuantity) / float64(item.Quantity))
+		refundedVat := utils.RoundPrice(item.VatAmount * float64(item.RefundedQuantity) / float64(item.Quantity))
+		item.RefundedQuantity += refundItem.Quantity
+		amount := utils.RoundPrice(utils.RoundPrice(item.Price*float64(item.RefundedQuantity)/float64(item.Quantity)) - refunded)
+		vatAmount := utils.RoundPrice(utils.RoundPrice(item.VatAmount*float64(item.RefundedQuantity)/float64(item.Quantity)) - refundedVat)
+		refund.Items = append(refund.Items, model.RefundItem{
+			ItemId:    item.Id,
+			ProductId: item.ProductId,
+			Quantity:  refundItem.Quantity,
+			Amount:    amount,
+			VatAmount: vatAmount,
+			Lots:      lots,
+		})
+		refund.Total += amount
+		switch item.TaxClass {
+		case constant.TaxZero:
+			refund.ZeroRatedAmount += amount
+		case constant.TaxExempt:
+			refund.ExemptAmount += amount
+		default:
+			refund.VatableAmount += amount - vatAmount
+			refund.VatAmount += vatAmount
+		}
+	}
+	if len(refund.Items) == 0 {
+		return nil, errors.New("nothing to refund")
+	}
+	refund.Total = utils.RoundPrice(refund.Total)
+	refund.VatableAmount = utils.RoundPrice(refund.VatableAmount)
+	refund.ZeroRatedAmount = utils.RoundPrice(refund.ZeroRatedAmount)
+	refund.ExemptAmount = utils.RoundPrice(refund.ExemptAmount)
+	refund.VatAmount = utils.RoundPrice(refund.VatAmount)
+
+	if status == "" {
+		status = constant.REFUNDED
+		for _, item := range orderItems {
+			if item.RefundedQuantity < item.Quantity {
+				status = constant.PARTIALLY_REFUNDED
+				break
+			}
+		}
+	}
+	tenders, err := getRefundTenders(order, refund.Total, request.Type)
+	if err != nil {
+		return nil, err
+	}
+	refund.Tenders = tenders
+	result, err := orderEntity.CreateRefund(sessionCtx, refund)
+	if err != nil {
+		return nil, err
+	}
+	err = reverseOrderPoints(sessionCtx, pointEntity, order, result, status)
+	if err != nil {
+		return nil, err
+	}
+	_, err = orderEntity.UpdateStatusById(sessionCtx, order.Id.Hex(), status, request.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// getRefundTenders splits the refund across the paid tenders of the order in proportion to what each has left to
+// refund, none is paid back more than it took. A tender type asked for pays it all back. Refunds made before
+// they were split count against the tender getRefundPayment picks
+func getRefundTenders(order *model.OrderDetail, total float64, paymentType string) ([]model.RefundTender, error) {
+	var payments []model.Payment
+	remaining := map[primitive.ObjectID]float64{}
+	for _, payment := range order.Payments {
+		if payment.Status != constant.PENDING {
+			payments = append(payments, payment)
+			remaining[payment.Id] = payment.Total
+		}
+	}
+	for _, refund := range order.Refunds {
+		if len(refund.Tenders) == 0 {
+			payment, err := getRefundPayment(payments, "")
+			if err != nil {
+				return nil, err
+			}
+			remaining[payment.Id] -= refund.Total
+		}
+		for _, tender := range refund.Tenders {
+			remaining[tender.PaymentId] -= tender.Amount
+		}
+	}
+
+	if paymentType != "" {
+		payment, err := getRefundPayment(payments, paymentType)
+		if err != nil {
+			return nil, err
+		}
+		if total-remaining[payment.Id] > priceTolerance {
+			return nil, fmt.Errorf("%s has %.2f left to refund", paymentType, math.Max(remaining[payment.Id], 0))
+		}
+		return []model.RefundTender{{PaymentId: payment.Id, Type: payment.Type, Amount: total}}, nil
+	}
+
+	left := 0.0
+	for _, payment := range payments {
+		left += math.Max(remaining[payment.Id], 0)
+	}
+	if left <= 0 {
+		return nil, errors.New("nothing left to refund")
+	}
+	amount := math.Min(total, left)
+	var tenders []model.RefundTender
+	allocated := 0.0
+	for _, payment := range payments {
+		if remaining[payment.Id] <= 0 {
+			continue
+		}
+		share := utils.RoundPrice(amount * remaining[payment.Id] / left)
+		tenders = append(tenders, model.RefundTender{PaymentId: payment.Id, Type: payment.Type, Amount: share})
+		allocated += share
+	}
+	// the rounding difference goes to the last tender that can take it
+	difference := utils.RoundPrice(amount - allocated)
+	for index := len(tenders) - 1; index >= 0 && difference != 0; index-- {
+		tender := &tenders[index]
+		share := utils.RoundPrice(math.Min(tender.Amount+difference, remaining[tender.PaymentId]))
+		difference = utils.RoundPrice(difference - (share - tender.Amount))
+		tender.Amount = share
+	}
+	return tenders, nil
+}
+
+// getRefundPayment picks the tender the refund is paid back through, cash or else the first tender
+// unless a type is asked for
+func getRefundPayment(payments []model.Payment, paymentType string) (*model.Payment, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("payment not found")
+	}
+	search := paymentType
+	if search == "" {
+		search = constant.CASH
+	}
+	for index := range payments {
+		if payments[index].Type == search {
+			return &payments[index], nil
+		}
+	}
+	if paymentType == "" {
+		return &payments[0], nil
+	}
+	return nil, fmt.Errorf("order was not paid by %s", paymentType)
+}
+
+// getRefundMessage lists the refunded items for the LINE notification
+func getRefundMessage(order *model.OrderDetail, refund *model.Refund) string {
+	var message = ""
+	var no = 1
+	for _, refundItem := range refund.Items {
+		for _, item := range order.Items {
+			if item.Id == refundItem.ItemId {
+				item.Quantity = refundItem.Quantity
+				item.Price = refundItem.Amount
+				message += fmt.Sprintf("%d. %s\n", no, item.GetMessage())
+				no += 1
+			}
+		}
+	}
+	message += fmt.Sprintf("\nรวม %.2f บาท", refund.Total)
+	if refund.Reason != "" {
+		message += "\nเหตุผล " + refund.Reason
+	}
+	return message
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	repository6 "devper/app/featues/loyalty/repository"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	repository2 "devper/app/featues/product/repository"
+	repository7 "devper/app/featues/shift/repository"
+	"devper/db"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+func RefundOrderById(transaction db.ITransaction, orderEntity repository.IOrder, productEntity repository2.IProduct, pointEntity repository6.IPoint, shiftEntity repository7.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		request := form.Refund{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CreatedBy = ctx.GetString("UserId")
+		request.ShiftId = getShiftId(shiftEntity, request.CreatedBy)
+
+		var order *model.OrderDetail
+		var result *model.Refund
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			var err error
+			order, err = orderEntity.GetOrderDetailById(sessionCtx, orderId)
+			if err != nil {
+				return err
+			}
+			refund, err := refundOrder(sessionCtx, orderEntity, productEntity, pointEntity, order, request, "")
+			if err != nil {
+				return err
+			}
+			result = refund
+			return nil
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		date := utils.ToFormat(order.CreatedDate)
+		_, _ = utils.NotifyMassage("คืนสินค้ารายการวันที่ " + date + "\n\n" + getRefundMessage(order, result))
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	repository6 "devper/app/featues/loyalty/repository"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	repository2 "devper/app/featues/product/repository"
+	repository7 "devper/app/featues/shift/repository"
+	"devper/db"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+func RefundOrderItemById(transaction db.ITransaction, orderEntity repository.IOrder, productEntity repository2.IProduct, pointEntity repository6.IPoint, shiftEntity repository7.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		itemId := ctx.Param("itemId")
+		request := form.VoidOrder{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c, cancel := utils.InitContext()
+		defer cancel()
+		item, err := orderEntity.GetOrderItemById(c, itemId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		shiftId := getShiftId(shiftEntity, ctx.GetString("UserId"))
+
+		var order *model.OrderDetail
+		var result *model.Refund
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			var err error
+			order, err = orderEntity.GetOrderDetailById(sessionCtx, item.OrderId.Hex())
+			if err != nil {
+				return err
+			}
+			refundRequest := form.Refund{
+				Reason:    request.Reason,
+				ShiftId:   shiftId,
+				CreatedBy: ctx.GetString("UserId"),
+			}
+			for _, orderItem := range order.Items {
+				if orderItem.Id.Hex() == itemId {
+					refundRequest.Items = append(refundRequest.Items, form.RefundItem{
+						ItemId:   itemId,
+						Quantity: orderItem.Quantity - orderItem.RefundedQuantity,
+					})
+				}
+			}
+			refund, err := refundOrder(sessionCtx, orderEntity, productEntity, pointEntity, order, refundRequest, "")
+			if err != nil {
+				return err
+			}
+			result = refund
+			return nil
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		date := utils.ToFormat(order.CreatedDate)
+		_, _ = utils.NotifyMassage("ยกเลิกสินค้ารายการวันที่ " + date + "\n\n" + getRefundMessage(order, result))
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"devper/app/core/constant"
+	model2 "devper/app/featues/loyalty/model"
+	"devper/app/featues/loyalty/repository"
+	"devper/app/featues/order/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"os"
+	"testing"
+)
+
+// stubPoint keeps the ledger entries of one order in memory
+type stubPoint struct {
+	repository.IPoint
+	points  model2.OrderPoints
+	entries []model2.PointEntry
+}
+
+func (stub *stubPoint) GetOrderPoints(ctx context.Context, orderId primitive.ObjectID) (*model2.OrderPoints, error) {
+	points := stub.points
+	return &points, nil
+}
+
+func (stub *stubPoint) AddPoints(ctx context.Context, entry model2.PointEntry) (*model2.PointEntry, error) {
+	stub.entries = append(stub.entries, entry)
+	switch entry.Type {
+	case constant.PointReverse:
+		stub.points.Earned += entry.Points
+	case constant.PointReturn:
+		stub.points.Redeemed -= entry.Points
+	}
+	return &entry, nil
+}
+
+func (stub *stubPoint) getPoints(pointType string) int {
+	points := 0
+	for _, entry := range stub.entries {
+		if entry.Type == pointType {
+			points += entry.Points
+		}
+	}
+	return points
+}
+
+// getMixedOrder is a 100 baht member order paid 30 by points and 70 in cash
+func getMixedOrder() *model.OrderDetail {
+	order := &model.OrderDetail{
+		Id:           primitive.NewObjectID(),
+		CustomerId:   primitive.NewObjectID(),
+		Total:        100,
+		PointsEarned: 2,
+		Refunds:      []model.Refund{},
+	}
+	order.Payments = []model.Payment{
+		{Id: primitive.NewObjectID(), Type: constant.POINTS, Status: constant.ACTIVE, Amount: 30, Total: 30},
+		{Id: primitive.NewObjectID(), Type: constant.CASH, Status: constant.ACTIVE, Amount: 100, Change: 30, Total: 70},
+	}
+	return order
+}
+
+func getTenderAmounts(tenders []model.RefundTender) map[string]float64 {
+	amounts := map[string]float64{}
+	for _, tender := range tenders {
+		amounts[tender.Type] += tender.Amount
+	}
+	return amounts
+}
+
+func TestGetRefundTenders(t *testing.T) {
+	tests := []struct {
+		name        string
+		refunds     []model.Refund
+		total       float64
+		paymentType string
+		want        map[string]float64
+		wantErr     bool
+	}{
+		{
+			name:  "void splits by tender",
+			total: 100,
+			want:  map[string]float64{constant.POINTS: 30, constant.CASH: 70},
+		},
+		{
+			name:  "partial refund splits by tender",
+			total: 33.33,
+			want:  map[string]float64{constant.POINTS: 10, constant.CASH: 23.33},
+		},
+		{
+			name: "void after a partial refund pays back what is left",
+			refunds: []model.Refund{{Total: 50, Tenders: []model.RefundTender{
+				{Type: constant.POINTS, Amount: 15},
+				{Type: constant.CASH, Amount: 35},
+			}}},
+			total: 50,
+			want:  map[string]float64{constant.POINTS: 15, constant.CASH: 35},
+		},
+		{
+			name:    "refund made before tenders counts against cash",
+			refunds: []model.Refund{{Total: 70}},
+			total:   30,
+			want:    map[string]float64{constant.POINTS: 30},
+		},
+		{
+			name:        "tender asked for",
+			total:       20,
+			paymentType: constant.CASH,
+			want:        map[string]float64{constant.CASH: 20},
+		},
+		{
+			name:        "tender asked for is capped at what it paid",
+			total:       40,
+			paymentType: constant.POINTS,
+			wantErr:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			order := getMixedOrder()
+			for _, refund := range test.refunds {
+				for index := range refund.Tenders {
+					for _, payment := range order.Payments {
+						if payment.Type == refund.Tenders[index].Type {
+							refund.Tenders[index].PaymentId = payment.Id
+						}
+					}
+				}
+				order.Refunds = append(order.Refunds, refund)
+			}
+			tenders, err := getRefundTenders(order, test.total, test.paymentType)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("tenders = %+v, want an error", tenders)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := getTenderAmounts(tenders)
+			if len(got) != len(test.want) {
+				t.Fatalf("tenders = %v, want %v", got, test.want)
+			}
+			for paymentType, amount := range test.want {
+				if got[paymentType] != amount {
+					t.Errorf("%s = %.2f, want %.2f", paymentType, got[paymentType], amount)
+				}
+			}
+		})
+	}
+}
+
+func TestGetRefundTendersSkipsPending(t *testing.T) {
+	order := getMixedOrder()
+	order.Payments[1].Type = constant.PROMPTPAY
+	order.Payments[1].Status = constant.PENDING
+	tenders, err := getRefundTenders(order, 100, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := getTenderAmounts(tenders)
+	if len(got) != 1 || got[constant.POINTS] != 30 {
+		t.Errorf("tenders = %v, want only the 30 points paid", got)
+	}
+}
+
+func TestVoidMixedPointsCash(t *testing.T) {
+	_ = os.Setenv("LOYALTY_POINT_VALUE", "1")
+	defer os.Unsetenv("LOYALTY_POINT_VALUE")
+
+	order := getMixedOrder()
+	tenders, err := getRefundTenders(order, order.Total, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refund := &model.Refund{Id: primitive.NewObjectID(), Total: order.Total, Tenders: tenders}
+	point := &stubPoint{points: model2.OrderPoints{Earned: 2, Redeemed: 30}}
+	if err = reverseOrderPoints(context.Background(), point, order, refund, constant.VOIDED); err != nil {
+		t.Fatal(err)
+	}
+
+	amounts := getTenderAmounts(tenders)
+	if amounts[constant.CASH] != 70 {
+		t.Errorf("cash refunded = %.2f, want 70", amounts[constant.CASH])
+	}
+	if got := point.getPoints(constant.PointReturn); got != 30 {
+		t.Errorf("points given back = %d, want 30", got)
+	}
+	if got := point.getPoints(constant.PointReverse); got != -2 {
+		t.Errorf("points reversed = %d, want -2", got)
+	}
+}
+
+func TestPartialRefundMixedPointsCash(t *testing.T) {
+	_ = os.Setenv("LOYALTY_POINT_VALUE", "1")
+	defer os.Unsetenv("LOYALTY_POINT_VALUE")
+
+	order := getMixedOrder()
+	tenders, err := getRefundTenders(order, 50, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refund := &model.Refund{Id: primitive.NewObjectID(), Total: 50, Tenders: tenders}
+	point := &stubPoint{points: model2.OrderPoints{Earned: 2, Redeemed: 30}}
+	if err = reverseOrderPoints(context.Background(), point, order, refund, constant.PARTIALLY_REFUNDED); err != nil {
+		t.Fatal(err)
+	}
+	if got := point.getPoints(constant.PointReturn); got != 15 {
+		t.Errorf("points given back = %d, want the 15 of the points share", got)
+	}
+}
@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	repository7 "devper/app/featues/shift/repository"
+)
+
+// getShiftId is the open shift of the cashier, empty when they have not opened one
+func getShiftId(shiftEntity repository7.IShift, userId string) string {
+	shift, err := shiftEntity.GetOpenShiftByUserId(userId)
+	if err != nil {
+		return ""
+	}
+	return shift.Id.Hex()
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
+	"devper/app/featues/order/repository"
+	repository2 "devper/app/featues/product/repository"
+	"devper/db"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+func UpdateOrderItemById(transaction db.ITransaction, orderEntity repository.IOrder, productEntity repository2.IProduct) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderId := ctx.Param("orderId")
+		itemId := ctx.Param("itemId")
+		userId := ctx.GetString("UserId")
+		request := form.UpdateOrderItem{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		order, err := getOpenOrder(orderEntity, orderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c, cancel := utils.InitContext()
+		defer cancel()
+		item, err := orderEntity.GetOrderItemById(c, itemId)
+		if err != nil || item.OrderId.Hex() != orderId {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errItemNotInOrder.Error()})
+			return
+		}
+		orderItem := form.OrderItem{
+			ProductId: item.ProductId.Hex(),
+			Quantity:  request.Quantity,
+			Discount:  request.Discount,
+			Price:     request.Price,
+			UpdatedBy: userId,
+		}
+		if err := calculateOrderItem(productEntity, &orderItem, order.VatMode); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if orderItem.Quantity > item.Quantity {
+			added := form.OrderItem{ProductId: orderItem.ProductId, Quantity: orderItem.Quantity - item.Quantity}
+			if err := checkStock(productEntity, []form.OrderItem{added}); err != nil {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		var result *model.Order
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			// the item may have changed since it was priced, return what it holds now
+			item, err := orderEntity.GetOrderItemById(sessionCtx, itemId)
+			if err != nil || item.OrderId.Hex() != orderId {
+				return errItemNotInOrder
+			}
+			updated := orderItem
+			err = returnOrderItem(sessionCtx, productEntity, item.ProductId.Hex(), item.Quantity, item.Lots, getOrderMovement(constant.StockVoid, order.Id, order.OrderNumber, userId))
+			if err != nil {
+				return err
+			}
+			err = deductOrderItem(sessionCtx, productEntity, &updated, getOrderMovement(constant.StockSale, order.Id, order.OrderNumber, userId))
+			if err != nil {
+				return err
+			}
+			_, err = orderEntity.UpdateOrderItemById(sessionCtx, itemId, updated)
+			if err != nil {
+				return err
+			}
+			order, err := orderEntity.UpdateTotalOrderById(sessionCtx, orderId)
+			if err != nil {
+				return err
+			}
+			if order.Status != constant.OPEN {
+				return errOrderNotOpen
+			}
+			result = order
+			return nil
+		})
+		if errors.Is(err, repository2.ErrInsufficientStock) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
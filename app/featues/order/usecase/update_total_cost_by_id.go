@@ -1,34 +1,65 @@
 package usecase
 
 import (
+	"devper/app/core/constant"
 	"devper/app/featues/order/form"
+	"devper/app/featues/order/model"
 	"devper/app/featues/order/repository"
 	repository2 "devper/app/featues/product/repository"
+	"devper/db"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
 )
 
-func UpdateTotalCostById(orderEntity repository.IOrder, productEntity repository2.IProduct) gin.HandlerFunc {
+// getItemCosts re-costs each item of the order, FIFO from the lots it was sold from unless asked for another method
+func getItemCosts(productEntity repository2.IProduct, order *model.OrderDetail, method string) ([]float64, float64, error) {
+	if method == "" {
+		method = constant.CostFIFO
+	}
+	costs := make([]float64, len(order.Items))
+	totalCost := 0.0
+	for index, item := range order.Items {
+		costPrice, err := productEntity.GetCostPriceByMethod(item.ProductId.Hex(), item.Quantity, item.Lots, method)
+		if err != nil {
+			return nil, 0, err
+		}
+		costs[index] = costPrice
+		totalCost += costPrice
+	}
+	return costs, totalCost, nil
+}
+
+// UpdateTotalCostById re-costs the order items and the order total cost, all of them or none
+func UpdateTotalCostById(transaction db.ITransaction, orderEntity repository.IOrder, productEntity repository2.IProduct) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		orderId := ctx.Param("orderId")
-		order, err := orderEntity.GetOrderDetailById(orderId)
-		if err != nil {
+		request := form.UpdateTotalCost{}
+		if err := ctx.ShouldBind(&request); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		totalCost := 0.0
-		for _, item := range order.Items {
-			orderItem := form.OrderItem{
-				CostPrice: productEntity.GetTotalCostPrice(item.ProductId.Hex(), item.Quantity),
-				Quantity:  item.Quantity,
-				Price:     item.Price,
-				Discount:  item.Discount,
-				ProductId: item.ProductId.Hex(),
+		userId := ctx.GetString("UserId")
+
+		var result *model.Order
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			order, err := orderEntity.GetOrderDetailById(sessionCtx, orderId)
+			if err != nil {
+				return err
 			}
-			_, _ = orderEntity.UpdateOrderItemById(item.Id.Hex(), orderItem)
-			totalCost += orderItem.CostPrice
-		}
-		result, err := orderEntity.UpdateTotalCostOrderById(orderId, totalCost)
+			costs, totalCost, err := getItemCosts(productEntity, order, request.Method)
+			if err != nil {
+				return err
+			}
+			for index, item := range order.Items {
+				_, err = orderEntity.UpdateOrderItemCostById(sessionCtx, item.Id.Hex(), costs[index], userId)
+				if err != nil {
+					return err
+				}
+			}
+			result, err = orderEntity.UpdateTotalCostOrderById(sessionCtx, orderId, totalCost, userId)
+			return err
+		})
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
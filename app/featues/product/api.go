@@ -5,11 +5,13 @@ import (
 	"devper/app/featues/product/repository"
 	"devper/app/featues/product/usecase"
 	repository2 "devper/app/featues/user/repository"
+	"devper/db"
 	"devper/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
 func ApplyProductAPI(app *gin.RouterGroup,
+	transaction db.ITransaction,
 	productEntity repository.IProduct,
 	userEntity repository2.IUser,
 ) {
@@ -23,7 +25,7 @@ func ApplyProductAPI(app *gin.RouterGroup,
 	productRoute.POST("",
 		middlewares.RequireAuthenticated(userEntity),
 		middlewares.RequireAuthorization(constant.ADMIN),
-		usecase.CreateProduct(productEntity),
+		usecase.CreateProduct(transaction, productEntity),
 	)
 
 	productRoute.GET("/:productId",
@@ -33,7 +35,7 @@ func ApplyProductAPI(app *gin.RouterGroup,
 	productRoute.PUT("/:productId",
 		middlewares.RequireAuthenticated(userEntity),
 		middlewares.RequireAuthorization(constant.ADMIN),
-		usecase.UpdateProductById(productEntity),
+		usecase.UpdateProductById(transaction, productEntity),
 	)
 
 	productRoute.DELETE("/:productId",
@@ -50,11 +52,31 @@ func ApplyProductAPI(app *gin.RouterGroup,
 		usecase.GetLotsByProductId(productEntity),
 	)
 
+	productRoute.GET("/lot/expiring",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetExpiringLots(productEntity),
+	)
+
 	productRoute.PUT("/lot/:lotId",
-		usecase.UpdateLotById(productEntity),
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.UpdateLotById(transaction, productEntity),
 	)
 
 	productRoute.GET("/lot/:lotId",
 		usecase.GetLotById(productEntity),
 	)
+
+	productRoute.POST("/:productId/stock",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.AdjustStockById(productEntity),
+	)
+
+	productRoute.GET("/:productId/stock-card",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetStockCard(productEntity),
+	)
 }
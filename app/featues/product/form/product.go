@@ -1,5 +1,7 @@
 package form
 
+import "time"
+
 type Product struct {
 	Name         string  `json:"name" binding:"required"`
 	NameEn       string  `json:"nameEn"`
@@ -10,8 +12,11 @@ type Product struct {
 	Quantity     int     `json:"quantity"`
 	SerialNumber string  `json:"serialNumber" binding:"required"`
 	Category     string  `json:"category"`
+	TaxClass     string  `json:"taxClass" binding:"omitempty,oneof=VAT ZERO EXEMPT"`
 	LotNumber    string  `json:"lotNumber"`
 	ExpireDate   string  `json:"expireDate"`
+	SupplierId   string  `json:"supplierId"`
+	UpdatedBy    string
 }
 
 type UpdateProduct struct {
@@ -23,11 +28,32 @@ type UpdateProduct struct {
 	Unit        string  `json:"unit"`
 	Quantity    int     `json:"quantity"`
 	Category    string  `json:"category"`
+	TaxClass    string  `json:"taxClass" binding:"omitempty,oneof=VAT ZERO EXEMPT"`
+	UpdatedBy   string
 }
 
 type ProductLot struct {
 	Quantity   int     `json:"quantity" binding:"required"`
 	LotNumber  string  `json:"lotNumber" binding:"required"`
-	ExpireDate string  `json:"expireDate" binding:"required"`
+	ExpireDate string  `json:"expireDate" binding:"omitempty"`
 	CostPrice  float64 `json:"costPrice"  binding:"required"`
+	UpdatedBy  string
+}
+
+type AdjustStock struct {
+	Type      string `json:"type" binding:"required,oneof=ADJUST TRANSFER"`
+	Quantity  int    `json:"quantity" binding:"required"`
+	Note      string `json:"note" binding:"required"`
+	UpdatedBy string
+}
+
+type GetStockCard struct {
+	StartDate time.Time `form:"startDate" binding:"required"`
+	EndDate   time.Time `form:"endDate" binding:"required"`
+}
+
+// GetExpiringLots lists the lots expiring within Days, 30 when not given, and those already expired
+type GetExpiringLots struct {
+	Days    int  `form:"days" binding:"min=0"`
+	Expired bool `form:"expired"`
 }
@@ -0,0 +1,56 @@
+package product
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/product/form"
+	"devper/app/featues/product/repository"
+	"github.com/sirupsen/logrus"
+	"os"
+	"strconv"
+	"time"
+)
+
+// StartExpiryDigest pushes the lots expiring within EXPIRY_ALERT_DAYS, 30 when unset, to LINE once a day at
+// EXPIRY_DIGEST_TIME Bangkok time, 08:00 when unset
+func StartExpiryDigest(productEntity repository.IProduct) {
+	go func() {
+		for {
+			time.Sleep(time.Until(getDigestTime(time.Now())))
+			sendExpiryDigest(productEntity)
+		}
+	}()
+}
+
+// getDigestTime is the next digest time after now
+func getDigestTime(now time.Time) time.Time {
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	at, err := time.ParseInLocation("15:04", os.Getenv("EXPIRY_DIGEST_TIME"), location)
+	if err != nil {
+		at = time.Date(0, 1, 1, 8, 0, 0, 0, location)
+	}
+	now = now.In(location)
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, location)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func sendExpiryDigest(productEntity repository.IProduct) {
+	days, _ := strconv.Atoi(os.Getenv("EXPIRY_ALERT_DAYS"))
+	if days < 0 {
+		days = 0
+	}
+	report, err := productEntity.GetExpiringLots(form.GetExpiringLots{Days: days})
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+	if report.Lots == 0 {
+		return
+	}
+	_, err = utils.NotifyMassage(report.GetMessage())
+	if err != nil {
+		logrus.Error(err)
+	}
+}
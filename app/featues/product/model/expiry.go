@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExpiringLot is a lot still holding stock, DaysLeft counts Bangkok days to its expire date and goes negative once expired
+type ExpiringLot struct {
+	ProductLot   `bson:",inline"`
+	Name         string  `json:"name"`
+	SerialNumber string  `json:"serialNumber"`
+	Unit         string  `json:"unit"`
+	Value        float64 `json:"value"`
+	DaysLeft     int     `json:"daysLeft"`
+	Expired      bool    `json:"expired"`
+}
+
+type ExpiryReport struct {
+	Date            time.Time     `json:"date"`
+	Days            int           `json:"days"`
+	Lots            int           `json:"lots"`
+	Quantity        int           `json:"quantity"`
+	Value           float64       `json:"value"`
+	ExpiredLots     int           `json:"expiredLots"`
+	ExpiredQuantity int           `json:"expiredQuantity"`
+	ExpiredValue    float64       `json:"expiredValue"`
+	Items           []ExpiringLot `json:"items"`
+}
+
+const messageLots = 20
+
+// GetMessage builds the Thai near-expiry digest pushed to LINE, listing the first lots to expire
+func (report ExpiryReport) GetMessage() string {
+	message := fmt.Sprintf("สินค้าหมดอายุภายใน %d วัน %d ล็อต %d ชิ้น มูลค่า %.2f บาท\n", report.Days, report.Lots, report.Quantity, report.Value)
+	message += fmt.Sprintf("หมดอายุแล้ว %d ล็อต %d ชิ้น มูลค่า %.2f บาท\n", report.ExpiredLots, report.ExpiredQuantity, report.ExpiredValue)
+	for index, item := range report.Items {
+		if index == messageLots {
+			message += fmt.Sprintf("\nและอีก %d ล็อต", len(report.Items)-messageLots)
+			break
+		}
+		status := fmt.Sprintf("อีก %d วัน", item.DaysLeft)
+		if item.Expired {
+			status = "หมดอายุแล้ว"
+		}
+		message += fmt.Sprintf("\n%s ล็อต %s หมดอายุ %s (%s) คงเหลือ %d %s", item.Name, item.LotNumber, item.GetExpireDay(), status, item.Quantity, item.Unit)
+	}
+	return message
+}
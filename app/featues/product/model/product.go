@@ -1,7 +1,9 @@
 package model
 
 import (
+	"devper/app/core/constant"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
 	"time"
 )
 
@@ -16,21 +18,99 @@ type Product struct {
 	Quantity     int                `bson:"quantity" json:"quantity"`
 	SerialNumber string             `bson:"serialNumber" json:"serialNumber"`
 	Category     string             `bson:"category"  json:"category"`
+	TaxClass     string             `bson:"taxClass" json:"taxClass"`
 	CreatedBy    string             `bson:"createdBy" json:"createdBy"`
 	CreatedDate  time.Time          `bson:"createdDate" json:"createdDate"`
 	UpdatedBy    string             `bson:"updatedBy" json:"updatedBy"`
 	UpdatedDate  time.Time          `bson:"updatedDate" json:"updatedDate"`
 }
 
+// GetTaxClass treats products saved before tax classes existed as standard rate VAT
+func (product Product) GetTaxClass() string {
+	if product.TaxClass == "" {
+		return constant.TaxVat
+	}
+	return product.TaxClass
+}
+
 type ProductLot struct {
-	Id          primitive.ObjectID `bson:"_id" json:"id"`
-	ProductId   primitive.ObjectID `bson:"productId" json:"productId"`
-	LotNumber   string             `bson:"lotNumber" json:"lotNumber"`
-	CostPrice   float64            `bson:"costPrice" json:"costPrice"`
-	Quantity    int                `bson:"quantity" json:"quantity"`
-	ExpireDate  string             `bson:"expireDate" json:"expireDate"`
-	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
-	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
-	UpdatedBy   string             `bson:"updatedBy" json:"updatedBy"`
-	UpdatedDate time.Time          `bson:"updatedDate" json:"updatedDate"`
+	Id             primitive.ObjectID `bson:"_id" json:"id"`
+	ProductId      primitive.ObjectID `bson:"productId" json:"productId"`
+	LotNumber      string             `bson:"lotNumber" json:"lotNumber"`
+	CostPrice      float64            `bson:"costPrice" json:"costPrice"`
+	Quantity       int                `bson:"quantity" json:"quantity"`
+	ExpireDate     time.Time          `bson:"expireDate,omitempty" json:"expireDate"`
+	ExpireDateText string             `bson:"expireDateText,omitempty" json:"expireDateText,omitempty"`
+	SupplierId     primitive.ObjectID `bson:"supplierId,omitempty" json:"supplierId,omitempty"`
+	CreatedBy      string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate    time.Time          `bson:"createdDate" json:"createdDate"`
+	UpdatedBy      string             `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate    time.Time          `bson:"updatedDate" json:"updatedDate"`
+}
+
+// LotAllocation keeps the expire date as the day it read when the lot was allocated
+type LotAllocation struct {
+	LotId      primitive.ObjectID `bson:"lotId" json:"lotId"`
+	LotNumber  string             `bson:"lotNumber" json:"lotNumber"`
+	ExpireDate string             `bson:"expireDate" json:"expireDate"`
+	CostPrice  float64            `bson:"costPrice" json:"costPrice"`
+	Quantity   int                `bson:"quantity" json:"quantity"`
+}
+
+// GetLotsCostPrice costs the allocated lots, units no lot covered fall back to unitCost
+func GetLotsCostPrice(lots []LotAllocation, quantity int, unitCost float64) float64 {
+	costPrice := 0.0
+	for _, lot := range lots {
+		costPrice += lot.CostPrice * float64(lot.Quantity)
+		quantity -= lot.Quantity
+	}
+	if quantity > 0 {
+		costPrice += unitCost * float64(quantity)
+	}
+	return costPrice
+}
+
+var expireDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02",
+	"02/01/2006",
+	"2/1/2006",
+	"2006-01",
+	"01/2006",
+}
+
+// ParseExpireDate reads an expire date written in one of the layouts lots were entered with, as a Bangkok day
+func ParseExpireDate(value string) (time.Time, bool) {
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	for _, layout := range expireDateLayouts {
+		expireTime, err := time.ParseInLocation(layout, strings.TrimSpace(value), location)
+		if err == nil {
+			return expireTime, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// GetExpireDateUpdate is the update migrating an expire date saved as text at field into a date, a text that
+// reads as no date is kept at field+"Text" to be corrected by hand and ok is false
+func GetExpireDateUpdate(field string, value string) (primitive.M, bool) {
+	if expireDate, ok := ParseExpireDate(value); ok {
+		return primitive.M{"$set": primitive.M{field: expireDate}}, true
+	}
+	if strings.TrimSpace(value) == "" {
+		return primitive.M{"$unset": primitive.M{field: ""}}, true
+	}
+	return primitive.M{
+		"$set":   primitive.M{field + "Text": value},
+		"$unset": primitive.M{field: ""},
+	}, false
+}
+
+// GetExpireDay is the Bangkok day the lot expires on, empty for a lot without an expire date
+func (lot ProductLot) GetExpireDay() string {
+	if lot.ExpireDate.IsZero() {
+		return ""
+	}
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	return lot.ExpireDate.In(location).Format("2006-01-02")
 }
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// StockMovement is one change to a product's quantity, delta is signed and balance is the quantity after it
+type StockMovement struct {
+	Id          primitive.ObjectID `bson:"_id" json:"id"`
+	ProductId   primitive.ObjectID `bson:"productId" json:"productId"`
+	LotId       primitive.ObjectID `bson:"lotId,omitempty" json:"lotId,omitempty"`
+	LotNumber   string             `bson:"lotNumber,omitempty" json:"lotNumber,omitempty"`
+	Lots        []LotAllocation    `bson:"lots,omitempty" json:"lots,omitempty"`
+	Type        string             `bson:"type" json:"type"`
+	Delta       int                `bson:"delta" json:"delta"`
+	Balance     int                `bson:"balance" json:"balance"`
+	RefType     string             `bson:"refType" json:"refType"`
+	RefId       primitive.ObjectID `bson:"refId,omitempty" json:"refId,omitempty"`
+	RefNumber   string             `bson:"refNumber,omitempty" json:"refNumber,omitempty"`
+	Note        string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
+}
+
+type StockCard struct {
+	ProductId      primitive.ObjectID `json:"productId"`
+	Name           string             `json:"name"`
+	Unit           string             `json:"unit"`
+	StartDate      time.Time          `json:"startDate"`
+	EndDate        time.Time          `json:"endDate"`
+	OpeningBalance int                `json:"openingBalance"`
+	In             int                `json:"in"`
+	Out            int                `json:"out"`
+	ClosingBalance int                `json:"closingBalance"`
+	Movements      []StockMovement    `json:"movements"`
+}
@@ -1,22 +1,33 @@
 package repository
 
 import (
+	"context"
+	"devper/app/core/constant"
 	"devper/app/core/utils"
 	"devper/app/featues/product/form"
 	"devper/app/featues/product/model"
 	"devper/db"
+	"errors"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"math"
+	"sort"
 	"strings"
 	"time"
 )
 
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrExpireDateInvalid = errors.New("expire date is invalid")
+)
+
 type productEntity struct {
-	productRepo *mongo.Collection
-	lotRepo     *mongo.Collection
+	productRepo  *mongo.Collection
+	lotRepo      *mongo.Collection
+	movementRepo *mongo.Collection
 }
 
 type IProduct interface {
@@ -24,24 +35,35 @@ type IProduct interface {
 	GetProductAll() ([]model.Product, error)
 	GetProductBySerialNumber(serialNumber string) (*model.Product, error)
 	GetProductById(id string) (*model.Product, error)
-	CreateProduct(form form.Product) (*model.Product, error)
+	CreateProduct(ctx context.Context, form form.Product) (*model.Product, error)
 	RemoveProductById(id string) (*model.Product, error)
-	UpdateProductById(id string, form form.UpdateProduct) (*model.Product, error)
-	RemoveQuantityById(id string, quantity int) (*model.Product, error)
-	AddQuantityById(id string, quantity int) (*model.Product, error)
-	GetTotalCostPrice(id string, quantity int) float64
+	UpdateProductById(ctx context.Context, id string, form form.UpdateProduct) (*model.Product, error)
+	RemoveQuantityById(ctx context.Context, id string, quantity int, allowOversell bool, movement model.StockMovement) (*model.Product, error)
+	AddQuantityById(ctx context.Context, id string, quantity int, movement model.StockMovement) (*model.Product, error)
+	GetCostPriceByMethod(id string, quantity int, lots []model.LotAllocation, method string) (float64, error)
 
-	CreateLot(productId string, form form.Product) (*model.ProductLot, error)
+	CreateLot(ctx context.Context, productId string, form form.Product) (*model.ProductLot, error)
 	GetLotAllByProductId(productId string) ([]model.ProductLot, error)
 	GetLotById(id string) (*model.ProductLot, error)
-	UpdateLotById(id string, form form.ProductLot) (*model.ProductLot, error)
+	UpdateLotById(ctx context.Context, id string, form form.ProductLot) (*model.ProductLot, error)
+	AllocateLots(ctx context.Context, productId string, quantity int) ([]model.LotAllocation, error)
+	ReturnLots(ctx context.Context, lots []model.LotAllocation) error
+	AdjustLotById(ctx context.Context, id string, quantity int) (*model.ProductLot, error)
+	ReceiveLot(ctx context.Context, lot model.ProductLot, movement model.StockMovement) (*model.ProductLot, error)
+
+	GetExpiringLots(form form.GetExpiringLots) (*model.ExpiryReport, error)
+	MigrateExpireDates() (int, error)
+
+	GetStockCard(productId string, form form.GetStockCard) (*model.StockCard, error)
 }
 
 func NewProductEntity(resource *db.Resource) IProduct {
 	productRepo := resource.DB.Collection("products")
 	lotRepo := resource.DB.Collection("product_lots")
-	var entity IProduct = &productEntity{productRepo: productRepo, lotRepo: lotRepo}
+	movementRepo := resource.DB.Collection("stock_movements")
+	var entity IProduct = &productEntity{productRepo: productRepo, lotRepo: lotRepo, movementRepo: movementRepo}
 	_, _ = entity.CreateIndex()
+	_, _ = entity.MigrateExpireDates()
 	return entity
 }
 
@@ -55,6 +77,18 @@ func (entity *productEntity) CreateIndex() (string, error) {
 		Options: options.Index().SetUnique(true),
 	}
 	ind, err := entity.productRepo.Indexes().CreateOne(ctx, mod)
+	if err != nil {
+		return "", err
+	}
+	_, err = entity.movementRepo.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "productId", Value: 1}, {Key: "createdDate", Value: 1}},
+	})
+	if err != nil {
+		return "", err
+	}
+	_, err = entity.lotRepo.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"expireDate": 1},
+	})
 	return ind, err
 }
 
@@ -95,32 +129,68 @@ func (entity *productEntity) GetProductBySerialNumber(serialNumber string) (*mod
 	return &data, nil
 }
 
-func (entity *productEntity) CreateProduct(form form.Product) (*model.Product, error) {
+// getExpireDate reads the expire date of a lot form, a lot may have none
+func getExpireDate(value string) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, nil
+	}
+	expireDate, ok := model.ParseExpireDate(value)
+	if !ok {
+		return time.Time{}, ErrExpireDateInvalid
+	}
+	return expireDate, nil
+}
+
+// getTaxClass defaults products to standard rate VAT
+func getTaxClass(taxClass string) string {
+	if taxClass == "" {
+		return constant.TaxVat
+	}
+	return taxClass
+}
+
+// CreateProduct adds a product, or receives into the one with the same serial number, with the quantity as a new lot
+func (entity *productEntity) CreateProduct(ctx context.Context, form form.Product) (*model.Product, error) {
 	logrus.Info("CreateProduct")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
+	if _, err := getExpireDate(form.ExpireDate); err != nil {
+		return nil, err
+	}
 	serialNumber := strings.TrimSpace(form.SerialNumber)
-	data, _ := entity.GetProductBySerialNumber(serialNumber)
-	if data != nil {
-		data.Name = form.Name
-		data.NameEn = form.NameEn
-		data.Description = form.Description
-		data.SerialNumber = serialNumber
-		data.Price = form.Price
-		data.CostPrice = form.CostPrice
-		data.Unit = form.Unit
-		data.Quantity = data.Quantity + form.Quantity
-		data.UpdatedDate = time.Now()
+	var data *model.Product
+	count, err := entity.productRepo.CountDocuments(ctx, bson.M{"serialNumber": serialNumber})
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		update := bson.M{
+			"$set": bson.M{
+				"name":        form.Name,
+				"nameEn":      form.NameEn,
+				"description": form.Description,
+				"price":       form.Price,
+				"costPrice":   form.CostPrice,
+				"unit":        form.Unit,
+				"category":    form.Category,
+				"taxClass":    getTaxClass(form.TaxClass),
+				"updatedBy":   form.UpdatedBy,
+				"updatedDate": time.Now(),
+			},
+			"$inc": bson.M{"quantity": form.Quantity},
+		}
 
 		isReturnNewDoc := options.After
 		opts := &options.FindOneAndUpdateOptions{
 			ReturnDocument: &isReturnNewDoc,
 		}
-		err := entity.productRepo.FindOneAndUpdate(ctx, bson.M{"_id": data.Id}, bson.M{"$set": data}, opts).Decode(&data)
+		err := entity.productRepo.FindOneAndUpdate(ctx, bson.M{"serialNumber": serialNumber}, update, opts).Decode(&data)
+		if err != nil {
+			return nil, err
+		}
+		lot, err := entity.CreateLot(ctx, data.Id.Hex(), form)
 		if err != nil {
 			return nil, err
 		}
-		_, err = entity.CreateLot(data.Id.Hex(), form)
+		err = entity.createReceiveMovement(ctx, data, lot, form)
 		if err != nil {
 			return nil, err
 		}
@@ -135,14 +205,22 @@ func (entity *productEntity) CreateProduct(form form.Product) (*model.Product, e
 		data.Unit = form.Unit
 		data.Price = form.Price
 		data.CostPrice = form.CostPrice
+		data.Category = form.Category
+		data.TaxClass = getTaxClass(form.TaxClass)
 		data.Quantity = form.Quantity
+		data.CreatedBy = form.UpdatedBy
 		data.CreatedDate = time.Now()
+		data.UpdatedBy = form.UpdatedBy
 		data.UpdatedDate = time.Now()
 		_, err := entity.productRepo.InsertOne(ctx, data)
 		if err != nil {
 			return nil, err
 		}
-		_, err = entity.CreateLot(data.Id.Hex(), form)
+		lot, err := entity.CreateLot(ctx, data.Id.Hex(), form)
+		if err != nil {
+			return nil, err
+		}
+		err = entity.createReceiveMovement(ctx, &data, lot, form)
 		if err != nil {
 			return nil, err
 		}
@@ -150,6 +228,33 @@ func (entity *productEntity) CreateProduct(form form.Product) (*model.Product, e
 	}
 }
 
+// createReceiveMovement records the quantity a product create brought in against the lot it created
+func (entity *productEntity) createReceiveMovement(ctx context.Context, product *model.Product, lot *model.ProductLot, form form.Product) error {
+	if form.Quantity == 0 {
+		return nil
+	}
+	return entity.createMovement(ctx, product, form.Quantity, model.StockMovement{
+		LotId:     lot.Id,
+		LotNumber: lot.LotNumber,
+		Type:      constant.StockReceive,
+		RefType:   constant.RefLot,
+		RefId:     lot.Id,
+		RefNumber: lot.LotNumber,
+		CreatedBy: form.UpdatedBy,
+	})
+}
+
+// createMovement appends the movement of delta units to the stock card, product holds the balance after it
+func (entity *productEntity) createMovement(ctx context.Context, product *model.Product, delta int, movement model.StockMovement) error {
+	movement.Id = primitive.NewObjectID()
+	movement.ProductId = product.Id
+	movement.Delta = delta
+	movement.Balance = product.Quantity
+	movement.CreatedDate = time.Now()
+	_, err := entity.movementRepo.InsertOne(ctx, movement)
+	return err
+}
+
 func (entity *productEntity) GetProductById(id string) (*model.Product, error) {
 	logrus.Info("GetProductById")
 	ctx, cancel := utils.InitContext()
@@ -181,104 +286,175 @@ func (entity *productEntity) RemoveProductById(id string) (*model.Product, error
 	return &data, nil
 }
 
-func (entity *productEntity) UpdateProductById(id string, form form.UpdateProduct) (*model.Product, error) {
+// UpdateProductById edits a product, a change of its quantity goes in as the difference from the stock read in ctx
+// and is recorded as an adjustment
+func (entity *productEntity) UpdateProductById(ctx context.Context, id string, form form.UpdateProduct) (*model.Product, error) {
 	logrus.Info("UpdateProductById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
 	objId, _ := primitive.ObjectIDFromHex(id)
-	data, err := entity.GetProductById(id)
+	var data model.Product
+	err := entity.productRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
-	data.Name = form.Name
-	data.NameEn = form.NameEn
-	data.Description = form.Description
-	data.Price = form.Price
-	data.CostPrice = form.CostPrice
-	data.Unit = form.Unit
-	data.Quantity = form.Quantity
-	data.UpdatedDate = time.Now()
+	delta := form.Quantity - data.Quantity
+	update := bson.M{
+		"$set": bson.M{
+			"name":        form.Name,
+			"nameEn":      form.NameEn,
+			"description": form.Description,
+			"price":       form.Price,
+			"costPrice":   form.CostPrice,
+			"unit":        form.Unit,
+			"category":    form.Category,
+			"taxClass":    getTaxClass(form.TaxClass),
+			"updatedBy":   form.UpdatedBy,
+			"updatedDate": time.Now(),
+		},
+		"$inc": bson.M{"quantity": delta},
+	}
 
 	isReturnNewDoc := options.After
 	opts := &options.FindOneAndUpdateOptions{
 		ReturnDocument: &isReturnNewDoc,
 	}
-	err = entity.productRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, bson.M{"$set": data}, opts).Decode(&data)
+	err = entity.productRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	if delta != 0 {
+		err = entity.createMovement(ctx, &data, delta, model.StockMovement{
+			Type:      constant.StockAdjust,
+			RefType:   constant.RefProduct,
+			RefId:     data.Id,
+			RefNumber: data.SerialNumber,
+			CreatedBy: form.UpdatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &data, nil
 }
 
-func (entity *productEntity) RemoveQuantityById(id string, quantity int) (*model.Product, error) {
+// RemoveQuantityById takes quantity out of stock and records it on the stock card with the movement's reference
+func (entity *productEntity) RemoveQuantityById(ctx context.Context, id string, quantity int, allowOversell bool, movement model.StockMovement) (*model.Product, error) {
 	logrus.Info("RemoveQuantityById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
 	objId, _ := primitive.ObjectIDFromHex(id)
 
-	data, err := entity.GetProductById(id)
-	if err != nil {
-		return nil, err
+	filter := bson.M{"_id": objId}
+	if !allowOversell {
+		filter["quantity"] = bson.M{"$gte": quantity}
+	}
+	update := bson.M{
+		"$inc": bson.M{"quantity": -quantity},
+		"$set": bson.M{"updatedDate": time.Now()},
 	}
-	data.Quantity = data.Quantity - quantity
-	data.UpdatedDate = time.Now()
-
 	isReturnNewDoc := options.After
 	opts := &options.FindOneAndUpdateOptions{
 		ReturnDocument: &isReturnNewDoc,
 	}
-	err = entity.productRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, bson.M{"$set": data}, opts).Decode(&data)
+	var data model.Product
+	err := entity.productRepo.FindOneAndUpdate(ctx, filter, update, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments && !allowOversell {
+		count, _ := entity.productRepo.CountDocuments(ctx, bson.M{"_id": objId})
+		if count > 0 {
+			return nil, ErrInsufficientStock
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	err = entity.createMovement(ctx, &data, -quantity, movement)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
 }
 
-func (entity *productEntity) AddQuantityById(id string, quantity int) (*model.Product, error) {
+// AddQuantityById puts quantity into stock, negative to take it out unchecked, and records it on the stock card
+func (entity *productEntity) AddQuantityById(ctx context.Context, id string, quantity int, movement model.StockMovement) (*model.Product, error) {
 	logrus.Info("AddQuantityById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
 	objId, _ := primitive.ObjectIDFromHex(id)
-	data, err := entity.GetProductById(id)
-	if err != nil {
-		return nil, err
+	update := bson.M{
+		"$inc": bson.M{"quantity": quantity},
+		"$set": bson.M{"updatedDate": time.Now()},
 	}
-	data.Quantity = data.Quantity + quantity
-	data.UpdatedDate = time.Now()
 	isReturnNewDoc := options.After
 	opts := &options.FindOneAndUpdateOptions{
 		ReturnDocument: &isReturnNewDoc,
 	}
-	err = entity.productRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, bson.M{"$set": data}, opts).Decode(&data)
+	var data model.Product
+	err := entity.productRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	err = entity.createMovement(ctx, &data, quantity, movement)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	return &data, nil
 }
 
-func (entity *productEntity) GetTotalCostPrice(id string, quantity int) float64 {
-	logrus.Info("GetTotalCostPrice")
-	data, err := entity.GetProductById(id)
+// GetCostPriceByMethod re-costs quantity, FIFO from the lots the item was sold from, AVERAGE and LATEST
+// from the product's current lots
+func (entity *productEntity) GetCostPriceByMethod(id string, quantity int, lots []model.LotAllocation, method string) (float64, error) {
+	logrus.Info("GetCostPriceByMethod")
+	product, err := entity.GetProductById(id)
+	if err != nil {
+		return 0, err
+	}
+	if method == constant.CostFIFO {
+		return model.GetLotsCostPrice(lots, quantity, product.CostPrice), nil
+	}
+	currentLots, err := entity.GetLotAllByProductId(id)
 	if err != nil {
-		return 0
+		return 0, err
 	}
-	return data.CostPrice * float64(quantity)
+	sort.SliceStable(currentLots, func(i, j int) bool {
+		return currentLots[i].CreatedDate.Before(currentLots[j].CreatedDate)
+	})
+	switch method {
+	case constant.CostAverage:
+		totalCost := 0.0
+		totalQuantity := 0
+		for _, lot := range currentLots {
+			if lot.Quantity > 0 {
+				totalCost += lot.CostPrice * float64(lot.Quantity)
+				totalQuantity += lot.Quantity
+			}
+		}
+		if totalQuantity == 0 {
+			return product.CostPrice * float64(quantity), nil
+		}
+		return totalCost / float64(totalQuantity) * float64(quantity), nil
+	case constant.CostLatest:
+		if len(currentLots) == 0 {
+			return product.CostPrice * float64(quantity), nil
+		}
+		return currentLots[len(currentLots)-1].CostPrice * float64(quantity), nil
+	}
+	return 0, errors.New("cost method invalid")
 }
 
-func (entity *productEntity) CreateLot(productId string, form form.Product) (*model.ProductLot, error) {
+func (entity *productEntity) CreateLot(ctx context.Context, productId string, form form.Product) (*model.ProductLot, error) {
 	logrus.Info("CreateLot")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
+	expireDate, err := getExpireDate(form.ExpireDate)
+	if err != nil {
+		return nil, err
+	}
 	data := model.ProductLot{}
 	data.Id = primitive.NewObjectID()
 	data.ProductId, _ = primitive.ObjectIDFromHex(productId)
 	data.LotNumber = form.LotNumber
-	data.ExpireDate = form.ExpireDate
+	data.ExpireDate = expireDate
+	data.SupplierId, _ = primitive.ObjectIDFromHex(form.SupplierId)
 	data.Quantity = form.Quantity
 	data.CostPrice = form.CostPrice
+	data.CreatedBy = form.UpdatedBy
 	data.CreatedDate = time.Now()
+	data.UpdatedBy = form.UpdatedBy
 	data.UpdatedDate = time.Now()
-	_, err := entity.lotRepo.InsertOne(ctx, data)
+	_, err = entity.lotRepo.InsertOne(ctx, data)
 	if err != nil {
 		return nil, err
 	}
@@ -324,29 +500,387 @@ func (entity *productEntity) GetLotById(id string) (*model.ProductLot, error) {
 	return &data, nil
 }
 
-func (entity *productEntity) UpdateLotById(id string, form form.ProductLot) (*model.ProductLot, error) {
+// UpdateLotById corrects a lot, the supplier it came from stays as received, a change of its quantity goes in as the
+// difference from the lot read in ctx and moves the product quantity with it as an adjustment
+func (entity *productEntity) UpdateLotById(ctx context.Context, id string, form form.ProductLot) (*model.ProductLot, error) {
 	logrus.Info("UpdateLotById")
-	ctx, cancel := utils.InitContext()
-	defer cancel()
 	objId, _ := primitive.ObjectIDFromHex(id)
-	data, err := entity.GetLotById(id)
+	var data model.ProductLot
+	err := entity.lotRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
 	if err != nil {
 		return nil, err
 	}
+	expireDate, err := getExpireDate(form.ExpireDate)
+	if err != nil {
+		return nil, err
+	}
+	delta := form.Quantity - data.Quantity
 
-	data.LotNumber = form.LotNumber
-	data.ExpireDate = form.ExpireDate
-	data.Quantity = form.Quantity
-	data.CostPrice = form.CostPrice
-	data.UpdatedDate = time.Now()
+	set := bson.M{
+		"lotNumber":   form.LotNumber,
+		"costPrice":   form.CostPrice,
+		"updatedBy":   form.UpdatedBy,
+		"updatedDate": time.Now(),
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"quantity": delta}}
+	// a cleared date has to be unset, a date given replaces the unreadable text
+	if expireDate.IsZero() {
+		update["$unset"] = bson.M{"expireDate": ""}
+	} else {
+		set["expireDate"] = expireDate
+		update["$unset"] = bson.M{"expireDateText": ""}
+	}
+
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	err = entity.lotRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	if delta != 0 {
+		_, err = entity.AddQuantityById(ctx, data.ProductId.Hex(), delta, model.StockMovement{
+			LotId:     data.Id,
+			LotNumber: data.LotNumber,
+			Type:      constant.StockAdjust,
+			RefType:   constant.RefLot,
+			RefId:     data.Id,
+			RefNumber: data.LotNumber,
+			CreatedBy: form.UpdatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &data, nil
+}
+
+// AllocateLots takes quantity from the lots first-expiry-first-out, lots short of stock leave the rest unallocated
+func (entity *productEntity) AllocateLots(ctx context.Context, productId string, quantity int) ([]model.LotAllocation, error) {
+	logrus.Info("AllocateLots")
+	objId, _ := primitive.ObjectIDFromHex(productId)
+	cursor, err := entity.lotRepo.Find(ctx, bson.M{"productId": objId, "quantity": bson.M{"$gt": 0}})
+	if err != nil {
+		return nil, err
+	}
+	var lots []model.ProductLot
+	err = cursor.All(ctx, &lots)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(lots, func(i, j int) bool {
+		expireI, expireJ := lots[i].ExpireDate, lots[j].ExpireDate
+		if expireI.IsZero() != expireJ.IsZero() {
+			return expireJ.IsZero()
+		}
+		if !expireI.Equal(expireJ) {
+			return expireI.Before(expireJ)
+		}
+		return lots[i].CreatedDate.Before(lots[j].CreatedDate)
+	})
+
+	var allocations []model.LotAllocation
+	remaining := quantity
+	for _, lot := range lots {
+		if remaining <= 0 {
+			break
+		}
+		take := lot.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		update := bson.M{
+			"$inc": bson.M{"quantity": -take},
+			"$set": bson.M{"updatedDate": time.Now()},
+		}
+		result, err := entity.lotRepo.UpdateOne(ctx, bson.M{"_id": lot.Id, "quantity": bson.M{"$gte": take}}, update)
+		if err != nil {
+			return nil, err
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+		allocations = append(allocations, model.LotAllocation{
+			LotId:      lot.Id,
+			LotNumber:  lot.LotNumber,
+			ExpireDate: lot.GetExpireDay(),
+			CostPrice:  lot.CostPrice,
+			Quantity:   take,
+		})
+		remaining -= take
+	}
+	if allocations == nil {
+		allocations = []model.LotAllocation{}
+	}
+	return allocations, nil
+}
+
+func (entity *productEntity) ReturnLots(ctx context.Context, lots []model.LotAllocation) error {
+	logrus.Info("ReturnLots")
+	for _, lot := range lots {
+		update := bson.M{
+			"$inc": bson.M{"quantity": lot.Quantity},
+			"$set": bson.M{"updatedDate": time.Now()},
+		}
+		_, err := entity.lotRepo.UpdateOne(ctx, bson.M{"_id": lot.LotId}, update)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdjustLotById moves the lot quantity alone, the product quantity is adjusted by the caller
+func (entity *productEntity) AdjustLotById(ctx context.Context, id string, quantity int) (*model.ProductLot, error) {
+	logrus.Info("AdjustLotById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	update := bson.M{
+		"$inc": bson.M{"quantity": quantity},
+		"$set": bson.M{"updatedDate": time.Now()},
+	}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.ProductLot
+	err := entity.lotRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// ReceiveLot stores a received lot, brings its quantity into the product at the lot's cost and records it on the stock card
+func (entity *productEntity) ReceiveLot(ctx context.Context, lot model.ProductLot, movement model.StockMovement) (*model.ProductLot, error) {
+	logrus.Info("ReceiveLot")
+	lot.Id = primitive.NewObjectID()
+	lot.CreatedDate = time.Now()
+	lot.UpdatedBy = lot.CreatedBy
+	lot.UpdatedDate = time.Now()
+	_, err := entity.lotRepo.InsertOne(ctx, lot)
+	if err != nil {
+		return nil, err
+	}
 
+	update := bson.M{
+		"$inc": bson.M{"quantity": lot.Quantity},
+		"$set": bson.M{
+			"costPrice":   lot.CostPrice,
+			"updatedBy":   lot.CreatedBy,
+			"updatedDate": time.Now(),
+		},
+	}
 	isReturnNewDoc := options.After
 	opts := &options.FindOneAndUpdateOptions{
 		ReturnDocument: &isReturnNewDoc,
 	}
-	err = entity.lotRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, bson.M{"$set": data}, opts).Decode(&data)
+	var product model.Product
+	err = entity.productRepo.FindOneAndUpdate(ctx, bson.M{"_id": lot.ProductId}, update, opts).Decode(&product)
+	if err != nil {
+		return nil, err
+	}
+	movement.LotId = lot.Id
+	movement.LotNumber = lot.LotNumber
+	movement.Type = constant.StockReceive
+	err = entity.createMovement(ctx, &product, lot.Quantity, movement)
 	if err != nil {
 		return nil, err
 	}
-	return data, nil
+	return &lot, nil
+}
+
+// GetExpiringLots lists the lots with stock left that expire within the coming days counting today, or only those
+// already expired, soonest first with their remaining quantity valued at the lot cost
+func (entity *productEntity) GetExpiringLots(form form.GetExpiringLots) (*model.ExpiryReport, error) {
+	logrus.Info("GetExpiringLots")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	days := form.Days
+	if days == 0 {
+		days = 30
+	}
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	until := today.AddDate(0, 0, days)
+	if form.Expired {
+		until = today
+	}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"quantity":   bson.M{"$gt": 0},
+				"expireDate": bson.M{"$lt": until},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "products",
+				"localField":   "productId",
+				"foreignField": "_id",
+				"as":           "product",
+			},
+		},
+		{
+			"$unwind": "$product",
+		},
+		{
+			"$sort": bson.D{{Key: "expireDate", Value: 1}, {Key: "_id", Value: 1}},
+		},
+	}
+	cursor, err := entity.lotRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	report := model.ExpiryReport{
+		Date:  today,
+		Days:  days,
+		Items: []model.ExpiringLot{},
+	}
+	for cursor.Next(ctx) {
+		var data struct {
+			model.ProductLot `bson:",inline"`
+			Product          model.Product `bson:"product"`
+		}
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+			continue
+		}
+		item := model.ExpiringLot{
+			ProductLot:   data.ProductLot,
+			Name:         data.Product.Name,
+			SerialNumber: data.Product.SerialNumber,
+			Unit:         data.Product.Unit,
+			Value:        utils.RoundPrice(data.CostPrice * float64(data.Quantity)),
+			DaysLeft:     int(math.Floor(data.ExpireDate.Sub(today).Hours() / 24)),
+			Expired:      data.ExpireDate.Before(today),
+		}
+		report.Lots++
+		report.Quantity += item.Quantity
+		report.Value += item.Value
+		if item.Expired {
+			report.ExpiredLots++
+			report.ExpiredQuantity += item.Quantity
+			report.ExpiredValue += item.Value
+		}
+		report.Items = append(report.Items, item)
+	}
+	report.Value = utils.RoundPrice(report.Value)
+	report.ExpiredValue = utils.RoundPrice(report.ExpiredValue)
+	return &report, nil
+}
+
+// MigrateExpireDates turns the expire dates lots were saved with as free-form text into dates, a text that reads
+// as no date is kept as expireDateText and logged so the lot can be corrected by hand
+func (entity *productEntity) MigrateExpireDates() (int, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	cursor, err := entity.lotRepo.Find(ctx, bson.M{"expireDate": bson.M{"$type": "string"}})
+	if err != nil {
+		return 0, err
+	}
+	var lots []struct {
+		Id         primitive.ObjectID `bson:"_id"`
+		ExpireDate string             `bson:"expireDate"`
+	}
+	if err = cursor.All(ctx, &lots); err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, lot := range lots {
+		update, ok := model.GetExpireDateUpdate("expireDate", lot.ExpireDate)
+		if !ok {
+			logrus.Warnf("lot %s expire date %q is unreadable, kept as expireDateText", lot.Id.Hex(), lot.ExpireDate)
+		}
+		_, err = entity.lotRepo.UpdateOne(ctx, bson.M{"_id": lot.Id}, update)
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		logrus.Infof("migrated the expire date of %d lots", migrated)
+	}
+	return migrated, nil
+}
+
+// GetStockCard lists the product's movements in the window between the balance brought forward and the balance
+// carried on, movements before the ledger existed are taken as the difference to the current quantity
+func (entity *productEntity) GetStockCard(productId string, form form.GetStockCard) (*model.StockCard, error) {
+	logrus.Info("GetStockCard")
+	product, err := entity.GetProductById(productId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+
+	card := model.StockCard{
+		ProductId: product.Id,
+		Name:      product.Name,
+		Unit:      product.Unit,
+		StartDate: form.StartDate,
+		EndDate:   form.EndDate,
+	}
+	var last model.StockMovement
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdDate", Value: -1}, {Key: "_id", Value: -1}})
+	err = entity.movementRepo.FindOne(ctx, bson.M{"productId": product.Id, "createdDate": bson.M{"$lt": form.StartDate}}, opts).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	hasOpening := err == nil
+	card.OpeningBalance = last.Balance
+
+	findOpts := options.Find().SetSort(bson.D{{Key: "createdDate", Value: 1}, {Key: "_id", Value: 1}})
+	filter := bson.M{
+		"productId": product.Id,
+		"createdDate": bson.M{
+			"$gte": form.StartDate,
+			"$lt":  form.EndDate,
+		},
+	}
+	cursor, err := entity.movementRepo.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	var items []model.StockMovement
+	for cursor.Next(ctx) {
+		var data model.StockMovement
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.StockMovement{}
+	}
+	if !hasOpening {
+		var next model.StockMovement
+		opts := options.FindOne().SetSort(bson.D{{Key: "createdDate", Value: 1}, {Key: "_id", Value: 1}})
+		err = entity.movementRepo.FindOne(ctx, bson.M{"productId": product.Id, "createdDate": bson.M{"$gte": form.StartDate}}, opts).Decode(&next)
+		if err == nil {
+			card.OpeningBalance = next.Balance - next.Delta
+		} else if err == mongo.ErrNoDocuments {
+			card.OpeningBalance = product.Quantity
+		} else {
+			return nil, err
+		}
+	}
+	card.ClosingBalance = card.OpeningBalance
+	for _, item := range items {
+		if item.Delta > 0 {
+			card.In += item.Delta
+		} else {
+			card.Out -= item.Delta
+		}
+		card.ClosingBalance = item.Balance
+	}
+	card.Movements = items
+	return &card, nil
 }
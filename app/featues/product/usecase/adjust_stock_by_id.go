@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/product/form"
+	"devper/app/featues/product/model"
+	"devper/app/featues/product/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// AdjustStockById moves the quantity by hand, a shrinkage or a transfer to another branch, with the reason on the stock card
+func AdjustStockById(productEntity repository.IProduct) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.Param("productId")
+		request := form.AdjustStock{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		product, err := productEntity.GetProductById(id)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c, cancel := utils.InitContext()
+		defer cancel()
+		result, err := productEntity.AddQuantityById(c, id, request.Quantity, model.StockMovement{
+			Type:      request.Type,
+			RefType:   constant.RefProduct,
+			RefId:     product.Id,
+			RefNumber: product.SerialNumber,
+			Note:      request.Note,
+			CreatedBy: request.UpdatedBy,
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -2,19 +2,29 @@ package usecase
 
 import (
 	"devper/app/featues/product/form"
+	"devper/app/featues/product/model"
 	"devper/app/featues/product/repository"
+	"devper/db"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
 )
 
-func CreateProduct(productEntity repository.IProduct) gin.HandlerFunc {
+// CreateProduct adds the product with its first lot and the stock card entry for it, all of them or none
+func CreateProduct(transaction db.ITransaction, productEntity repository.IProduct) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		request := form.Product{}
 		if err := ctx.ShouldBind(&request); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		result, err := productEntity.CreateProduct(request)
+		request.UpdatedBy = ctx.GetString("UserId")
+		var result *model.Product
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			var err error
+			result, err = productEntity.CreateProduct(sessionCtx, request)
+			return err
+		})
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
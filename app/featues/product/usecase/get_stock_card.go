@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"devper/app/featues/product/form"
+	"devper/app/featues/product/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetStockCard(productEntity repository.IProduct) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.Param("productId")
+		request := form.GetStockCard{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := productEntity.GetStockCard(id, request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"devper/app/featues/product/form"
+	"devper/app/featues/product/model"
 	"devper/app/featues/product/repository"
+	"devper/db"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
 )
 
-func UpdateLotById(productEntity repository.IProduct) gin.HandlerFunc {
+// UpdateLotById corrects the lot and moves the product quantity and the stock card with it, all of them or none
+func UpdateLotById(transaction db.ITransaction, productEntity repository.IProduct) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		id := ctx.Param("lotId")
 		request := form.ProductLot{}
@@ -15,7 +19,13 @@ func UpdateLotById(productEntity repository.IProduct) gin.HandlerFunc {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		result, err := productEntity.UpdateLotById(id, request)
+		request.UpdatedBy = ctx.GetString("UserId")
+		var result *model.ProductLot
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			var err error
+			result, err = productEntity.UpdateLotById(sessionCtx, id, request)
+			return err
+		})
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
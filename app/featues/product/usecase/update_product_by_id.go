@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"devper/app/featues/product/form"
+	"devper/app/featues/product/model"
 	"devper/app/featues/product/repository"
+	"devper/db"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
 )
 
-func UpdateProductById(productEntity repository.IProduct) gin.HandlerFunc {
+// UpdateProductById edits the product and records a change of its quantity on the stock card, both or neither
+func UpdateProductById(transaction db.ITransaction, productEntity repository.IProduct) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		id := ctx.Param("productId")
 		request := form.UpdateProduct{}
@@ -15,7 +19,13 @@ func UpdateProductById(productEntity repository.IProduct) gin.HandlerFunc {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		result, err := productEntity.UpdateProductById(id, request)
+		request.UpdatedBy = ctx.GetString("UserId")
+		var result *model.Product
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			var err error
+			result, err = productEntity.UpdateProductById(sessionCtx, id, request)
+			return err
+		})
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
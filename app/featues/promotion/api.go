@@ -0,0 +1,50 @@
+package promotion
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/promotion/repository"
+	"devper/app/featues/promotion/usecase"
+	repository2 "devper/app/featues/user/repository"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplyPromotionAPI(
+	app *gin.RouterGroup,
+	promotionEntity repository.IPromotion,
+	userEntity repository2.IUser,
+) {
+	promotionRoute := app.Group("promotion")
+
+	promotionRoute.GET("",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetPromotions(promotionEntity),
+	)
+
+	promotionRoute.POST("",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CreatePromotion(promotionEntity),
+	)
+
+	promotionRoute.GET("/code/:code",
+		usecase.GetPromotionByCode(promotionEntity),
+	)
+
+	promotionRoute.GET("/:promotionId",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetPromotionById(promotionEntity),
+	)
+
+	promotionRoute.PUT("/:promotionId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.UpdatePromotionById(promotionEntity),
+	)
+
+	promotionRoute.DELETE("/:promotionId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.DeletePromotionById(promotionEntity),
+	)
+}
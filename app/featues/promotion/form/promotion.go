@@ -0,0 +1,21 @@
+package form
+
+import "time"
+
+type Promotion struct {
+	Name         string    `json:"name" binding:"required"`
+	Type         string    `json:"type" binding:"required,oneof=PERCENT FIXED BUY_X_GET_Y CATEGORY"`
+	Value        float64   `json:"value" binding:"min=0"`
+	MaxDiscount  float64   `json:"maxDiscount" binding:"min=0"`
+	MinTotal     float64   `json:"minTotal" binding:"min=0"`
+	ProductId    string    `json:"productId" binding:"required_if=Type BUY_X_GET_Y"`
+	BuyQuantity  int       `json:"buyQuantity" binding:"required_if=Type BUY_X_GET_Y,min=0"`
+	FreeQuantity int       `json:"freeQuantity" binding:"required_if=Type BUY_X_GET_Y,min=0"`
+	Category     string    `json:"category" binding:"required_if=Type CATEGORY"`
+	Code         string    `json:"code"`
+	UsageLimit   int       `json:"usageLimit" binding:"min=0"`
+	StartDate    time.Time `json:"startDate"`
+	EndDate      time.Time `json:"endDate"`
+	Status       string    `json:"status" binding:"omitempty,oneof=ACTIVE INACTIVE"`
+	UpdatedBy    string
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"devper/app/core/constant"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Promotion struct {
+	Id           primitive.ObjectID `bson:"_id" json:"id"`
+	Name         string             `bson:"name" json:"name"`
+	Type         string             `bson:"type" json:"type"`
+	Value        float64            `bson:"value" json:"value"`
+	MaxDiscount  float64            `bson:"maxDiscount" json:"maxDiscount"`
+	MinTotal     float64            `bson:"minTotal" json:"minTotal"`
+	ProductId    primitive.ObjectID `bson:"productId,omitempty" json:"productId"`
+	BuyQuantity  int                `bson:"buyQuantity" json:"buyQuantity"`
+	FreeQuantity int                `bson:"freeQuantity" json:"freeQuantity"`
+	Category     string             `bson:"category" json:"category"`
+	Code         string             `bson:"code" json:"code"`
+	UsageLimit   int                `bson:"usageLimit" json:"usageLimit"`
+	UsageCount   int                `bson:"usageCount" json:"usageCount"`
+	StartDate    time.Time          `bson:"startDate" json:"startDate"`
+	EndDate      time.Time          `bson:"endDate" json:"endDate"`
+	Status       string             `bson:"status" json:"status"`
+	CreatedBy    string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate  time.Time          `bson:"createdDate" json:"createdDate"`
+	UpdatedBy    string             `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate  time.Time          `bson:"updatedDate" json:"updatedDate"`
+}
+
+// IsAvailable tells whether the promotion is active, inside its window and not used up at date
+func (promotion Promotion) IsAvailable(date time.Time) bool {
+	if promotion.Status != constant.ACTIVE {
+		return false
+	}
+	if !promotion.StartDate.IsZero() && date.Before(promotion.StartDate) {
+		return false
+	}
+	if !promotion.EndDate.IsZero() && !date.Before(promotion.EndDate) {
+		return false
+	}
+	return promotion.UsageLimit == 0 || promotion.UsageCount < promotion.UsageLimit
+}
+
+type AppliedPromotion struct {
+	PromotionId primitive.ObjectID `bson:"promotionId" json:"promotionId"`
+	Name        string             `bson:"name" json:"name"`
+	Code        string             `bson:"code" json:"code"`
+	Amount      float64            `bson:"amount" json:"amount"`
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/promotion/form"
+	"devper/app/featues/promotion/model"
+	"devper/db"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strings"
+	"time"
+)
+
+var ErrPromotionUsedUp = errors.New("promotion is used up")
+
+type promotionEntity struct {
+	promotionRepo *mongo.Collection
+}
+
+type IPromotion interface {
+	CreateIndex() (string, error)
+	GetPromotionAll() ([]model.Promotion, error)
+	GetActivePromotions(date time.Time) ([]model.Promotion, error)
+	CreatePromotion(form form.Promotion) (*model.Promotion, error)
+	GetPromotionById(id string) (*model.Promotion, error)
+	GetPromotionByCode(code string) (*model.Promotion, error)
+	UpdatePromotionById(id string, form form.Promotion) (*model.Promotion, error)
+	RemovePromotionById(id string) (*model.Promotion, error)
+	UsePromotionById(ctx context.Context, id primitive.ObjectID) error
+}
+
+func NewPromotionEntity(resource *db.Resource) IPromotion {
+	promotionRepo := resource.DB.Collection("promotions")
+	var entity IPromotion = &promotionEntity{promotionRepo: promotionRepo}
+	_, _ = entity.CreateIndex()
+	return entity
+}
+
+func (entity *promotionEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mod := mongo.IndexModel{
+		Keys: bson.M{
+			"code": 1,
+		},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"code": bson.M{"$gt": ""},
+		}),
+	}
+	ind, err := entity.promotionRepo.Indexes().CreateOne(ctx, mod)
+	return ind, err
+}
+
+func (entity *promotionEntity) GetPromotionAll() ([]model.Promotion, error) {
+	logrus.Info("GetPromotionAll")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	return entity.findPromotions(ctx, bson.M{})
+}
+
+// GetActivePromotions lists the automatic promotions, the ones without a coupon code, running at date
+func (entity *promotionEntity) GetActivePromotions(date time.Time) ([]model.Promotion, error) {
+	logrus.Info("GetActivePromotions")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	items, err := entity.findPromotions(ctx, bson.M{"status": constant.ACTIVE, "code": ""})
+	if err != nil {
+		return nil, err
+	}
+	var promotions []model.Promotion
+	for _, item := range items {
+		if item.IsAvailable(date) {
+			promotions = append(promotions, item)
+		}
+	}
+	return promotions, nil
+}
+
+func (entity *promotionEntity) findPromotions(ctx context.Context, filter bson.M) ([]model.Promotion, error) {
+	var items []model.Promotion
+	opts := options.Find().SetSort(bson.D{{Key: "createdDate", Value: 1}})
+	cursor, err := entity.promotionRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.Promotion
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.Promotion{}
+	}
+	return items, nil
+}
+
+func setPromotion(data *model.Promotion, form form.Promotion) {
+	productId, _ := primitive.ObjectIDFromHex(form.ProductId)
+	data.Name = form.Name
+	data.Type = form.Type
+	data.Value = form.Value
+	data.MaxDiscount = form.MaxDiscount
+	data.MinTotal = form.MinTotal
+	data.ProductId = productId
+	data.BuyQuantity = form.BuyQuantity
+	data.FreeQuantity = form.FreeQuantity
+	data.Category = strings.ToUpper(form.Category)
+	data.Code = strings.ToUpper(strings.TrimSpace(form.Code))
+	data.UsageLimit = form.UsageLimit
+	data.StartDate = form.StartDate
+	data.EndDate = form.EndDate
+	data.Status = form.Status
+	if data.Status == "" {
+		data.Status = constant.ACTIVE
+	}
+	data.UpdatedBy = form.UpdatedBy
+	data.UpdatedDate = time.Now()
+}
+
+func (entity *promotionEntity) CreatePromotion(form form.Promotion) (*model.Promotion, error) {
+	logrus.Info("CreatePromotion")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	data := model.Promotion{
+		Id:          primitive.NewObjectID(),
+		CreatedBy:   form.UpdatedBy,
+		CreatedDate: time.Now(),
+	}
+	setPromotion(&data, form)
+	_, err := entity.promotionRepo.InsertOne(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *promotionEntity) GetPromotionById(id string) (*model.Promotion, error) {
+	logrus.Info("GetPromotionById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Promotion
+	err := entity.promotionRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *promotionEntity) GetPromotionByCode(code string) (*model.Promotion, error) {
+	logrus.Info("GetPromotionByCode")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	var data model.Promotion
+	err := entity.promotionRepo.FindOne(ctx, bson.M{"code": strings.ToUpper(strings.TrimSpace(code))}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *promotionEntity) UpdatePromotionById(id string, form form.Promotion) (*model.Promotion, error) {
+	logrus.Info("UpdatePromotionById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Promotion
+	err := entity.promotionRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	setPromotion(&data, form)
+
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	err = entity.promotionRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, bson.M{"$set": data}, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *promotionEntity) RemovePromotionById(id string) (*model.Promotion, error) {
+	logrus.Info("RemovePromotionById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	var data model.Promotion
+	objId, _ := primitive.ObjectIDFromHex(id)
+	err := entity.promotionRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	_, err = entity.promotionRepo.DeleteOne(ctx, bson.M{"_id": objId})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// UsePromotionById counts one more use, failing with ErrPromotionUsedUp once the usage limit is reached
+func (entity *promotionEntity) UsePromotionById(ctx context.Context, id primitive.ObjectID) error {
+	logrus.Info("UsePromotionById")
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"usageLimit": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$usageCount", "$usageLimit"}}},
+		},
+	}
+	update := bson.M{
+		"$inc": bson.M{"usageCount": 1},
+		"$set": bson.M{"updatedDate": time.Now()},
+	}
+	result, err := entity.promotionRepo.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrPromotionUsedUp
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"devper/app/featues/promotion/form"
+	"devper/app/featues/promotion/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func CreatePromotion(entity repository.IPromotion) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.Promotion{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		result, err := entity.CreatePromotion(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"devper/app/featues/promotion/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func DeletePromotionById(entity repository.IPromotion) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		promotionId := ctx.Param("promotionId")
+		result, err := entity.RemovePromotionById(promotionId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
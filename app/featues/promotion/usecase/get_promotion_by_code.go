@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"devper/app/featues/promotion/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// GetPromotionByCode checks a coupon at the till before checkout
+func GetPromotionByCode(entity repository.IPromotion) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		code := ctx.Param("code")
+		result, err := entity.GetPromotionByCode(code)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !result.IsAvailable(time.Now()) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "coupon " + result.Code + " is not available"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
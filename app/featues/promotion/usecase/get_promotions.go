@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"devper/app/featues/promotion/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetPromotions(entity repository.IPromotion) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		result, err := entity.GetPromotionAll()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
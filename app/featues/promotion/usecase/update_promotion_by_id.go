@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"devper/app/featues/promotion/form"
+	"devper/app/featues/promotion/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func UpdatePromotionById(entity repository.IPromotion) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		promotionId := ctx.Param("promotionId")
+		request := form.Promotion{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		result, err := entity.UpdatePromotionById(promotionId, request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,74 @@
+package purchase
+
+import (
+	"devper/app/core/constant"
+	repository3 "devper/app/featues/counter/repository"
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/purchase/repository"
+	"devper/app/featues/purchase/usecase"
+	repository5 "devper/app/featues/supplier/repository"
+	repository4 "devper/app/featues/user/repository"
+	"devper/db"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplyPurchaseAPI(
+	app *gin.RouterGroup,
+	transaction db.ITransaction,
+	purchaseEntity repository.IPurchase,
+	productEntity repository2.IProduct,
+	supplierEntity repository5.ISupplier,
+	counterEntity repository3.ICounter,
+	userEntity repository4.IUser,
+) {
+	purchaseRoute := app.Group("purchase-order")
+
+	purchaseRoute.POST("",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CreatePurchaseOrder(transaction, purchaseEntity, productEntity, supplierEntity, counterEntity),
+	)
+
+	purchaseRoute.GET("",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetPurchaseOrders(purchaseEntity),
+	)
+
+	purchaseRoute.GET("/:purchaseOrderId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetPurchaseOrderById(purchaseEntity),
+	)
+
+	purchaseRoute.PUT("/:purchaseOrderId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.UpdatePurchaseOrderById(purchaseEntity, productEntity, supplierEntity),
+	)
+
+	purchaseRoute.POST("/:purchaseOrderId/order",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.OrderPurchaseOrder(purchaseEntity),
+	)
+
+	purchaseRoute.POST("/:purchaseOrderId/cancel",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CancelPurchaseOrder(purchaseEntity),
+	)
+
+	purchaseRoute.POST("/:purchaseOrderId/receive",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.ReceiveGoods(transaction, purchaseEntity, productEntity, supplierEntity, counterEntity),
+	)
+
+	purchaseRoute.GET("/:purchaseOrderId/receipt",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetGoodsReceipts(purchaseEntity),
+	)
+}
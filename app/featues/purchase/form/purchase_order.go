@@ -0,0 +1,40 @@
+package form
+
+import "time"
+
+type PurchaseOrder struct {
+	SupplierId   string              `json:"supplierId" binding:"required"`
+	Items        []PurchaseOrderItem `json:"items" binding:"required,min=1,dive"`
+	Note         string              `json:"note"`
+	ExpectedDate time.Time           `json:"expectedDate"`
+	UpdatedBy    string
+}
+
+type PurchaseOrderItem struct {
+	ProductId string  `json:"productId" binding:"required"`
+	Quantity  int     `json:"quantity" binding:"required,min=1"`
+	CostPrice float64 `json:"costPrice" binding:"min=0"`
+}
+
+type GetPurchaseOrders struct {
+	Status     string `form:"status"`
+	SupplierId string `form:"supplierId"`
+	Page       int64  `form:"page"`
+	Size       int64  `form:"size"`
+}
+
+// ReceiveGoods is one delivery against the order, a product may come in several lots
+type ReceiveGoods struct {
+	Items     []ReceiveItem `json:"items" binding:"required,min=1,dive"`
+	Note      string        `json:"note"`
+	CreatedBy string
+}
+
+// ReceiveItem leaves CostPrice out to take the ordered cost
+type ReceiveItem struct {
+	ProductId  string   `json:"productId" binding:"required"`
+	Quantity   int      `json:"quantity" binding:"required,min=1"`
+	LotNumber  string   `json:"lotNumber" binding:"required"`
+	ExpireDate string   `json:"expireDate"`
+	CostPrice  *float64 `json:"costPrice" binding:"omitempty,min=0"`
+}
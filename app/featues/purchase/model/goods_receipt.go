@@ -0,0 +1,32 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type GoodsReceipt struct {
+	Id                  primitive.ObjectID `bson:"_id" json:"id"`
+	Number              int64              `bson:"number" json:"number"`
+	Branch              string             `bson:"branch" json:"branch"`
+	PurchaseOrderId     primitive.ObjectID `bson:"purchaseOrderId" json:"purchaseOrderId"`
+	PurchaseOrderNumber int64              `bson:"purchaseOrderNumber" json:"purchaseOrderNumber"`
+	SupplierId          primitive.ObjectID `bson:"supplierId" json:"supplierId"`
+	Items               []GoodsReceiptItem `bson:"items" json:"items"`
+	Total               float64            `bson:"total" json:"total"`
+	Note                string             `bson:"note" json:"note"`
+	CreatedBy           string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate         time.Time          `bson:"createdDate" json:"createdDate"`
+}
+
+// GoodsReceiptItem is one lot received, LotId is the product lot it was stored as
+type GoodsReceiptItem struct {
+	ProductId      primitive.ObjectID `bson:"productId" json:"productId"`
+	Name           string             `bson:"name" json:"name"`
+	LotId          primitive.ObjectID `bson:"lotId" json:"lotId"`
+	LotNumber      string             `bson:"lotNumber" json:"lotNumber"`
+	ExpireDate     time.Time          `bson:"expireDate,omitempty" json:"expireDate"`
+	ExpireDateText string             `bson:"expireDateText,omitempty" json:"expireDateText,omitempty"`
+	CostPrice      float64            `bson:"costPrice" json:"costPrice"`
+	Quantity       int                `bson:"quantity" json:"quantity"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"devper/app/core/constant"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type PurchaseOrder struct {
+	Id           primitive.ObjectID  `bson:"_id" json:"id"`
+	Number       int64               `bson:"number" json:"number"`
+	Branch       string              `bson:"branch" json:"branch"`
+	SupplierId   primitive.ObjectID  `bson:"supplierId" json:"supplierId"`
+	SupplierName string              `bson:"supplierName" json:"supplierName"`
+	Status       string              `bson:"status" json:"status"`
+	Items        []PurchaseOrderItem `bson:"items" json:"items"`
+	Total        float64             `bson:"total" json:"total"`
+	Note         string              `bson:"note" json:"note"`
+	ExpectedDate time.Time           `bson:"expectedDate" json:"expectedDate"`
+	OrderedBy    string              `bson:"orderedBy" json:"orderedBy"`
+	OrderedDate  time.Time           `bson:"orderedDate" json:"orderedDate"`
+	ReceivedDate time.Time           `bson:"receivedDate" json:"receivedDate"`
+	CreatedBy    string              `bson:"createdBy" json:"createdBy"`
+	CreatedDate  time.Time           `bson:"createdDate" json:"createdDate"`
+	UpdatedBy    string              `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate  time.Time           `bson:"updatedDate" json:"updatedDate"`
+}
+
+// PurchaseOrderItem is one product ordered, Outstanding is what is still to be received against it
+type PurchaseOrderItem struct {
+	ProductId        primitive.ObjectID `bson:"productId" json:"productId"`
+	SerialNumber     string             `bson:"serialNumber" json:"serialNumber"`
+	SupplierSku      string             `bson:"supplierSku" json:"supplierSku"`
+	Name             string             `bson:"name" json:"name"`
+	Unit             string             `bson:"unit" json:"unit"`
+	Quantity         int                `bson:"quantity" json:"quantity"`
+	ReceivedQuantity int                `bson:"receivedQuantity" json:"receivedQuantity"`
+	Outstanding      int                `bson:"-" json:"outstanding"`
+	CostPrice        float64            `bson:"costPrice" json:"costPrice"`
+	Total            float64            `bson:"total" json:"total"`
+}
+
+func (order *PurchaseOrder) SetOutstanding() {
+	for index := range order.Items {
+		item := &order.Items[index]
+		item.Outstanding = item.Quantity - item.ReceivedQuantity
+		if item.Outstanding < 0 {
+			item.Outstanding = 0
+		}
+	}
+}
+
+// GetItem finds the line of a product, nil when it was not ordered
+func (order *PurchaseOrder) GetItem(productId primitive.ObjectID) *PurchaseOrderItem {
+	for index := range order.Items {
+		if order.Items[index].ProductId == productId {
+			return &order.Items[index]
+		}
+	}
+	return nil
+}
+
+// GetReceivedStatus is partially received until every line is received in full
+func (order PurchaseOrder) GetReceivedStatus() string {
+	for _, item := range order.Items {
+		if item.ReceivedQuantity < item.Quantity {
+			return constant.PARTIALLY_RECEIVED
+		}
+	}
+	return constant.RECEIVED
+}
@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	model2 "devper/app/featues/product/model"
+	"devper/app/featues/purchase/form"
+	"devper/app/featues/purchase/model"
+	"devper/db"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+var (
+	ErrSupplierNotFound = errors.New("supplier is not found or inactive")
+	ErrProductNotFound  = errors.New("product is not found")
+	ErrDuplicateItem    = errors.New("product is ordered more than once")
+	ErrStatusInvalid    = errors.New("purchase order status does not allow this")
+	ErrItemNotOrdered   = errors.New("product is not on the purchase order")
+	ErrOverReceipt      = errors.New("received quantity is more than outstanding")
+)
+
+// nextStatuses lists the statuses a purchase order may be moved to by hand from each status
+var nextStatuses = map[string][]string{
+	constant.ORDERED:   {constant.DRAFT},
+	constant.CANCELLED: {constant.DRAFT, constant.ORDERED},
+}
+
+type purchaseEntity struct {
+	purchaseRepo *mongo.Collection
+	receiptRepo  *mongo.Collection
+}
+
+type IPurchase interface {
+	CreateIndex() (string, error)
+	CreatePurchaseOrder(ctx context.Context, order model.PurchaseOrder) (*model.PurchaseOrder, error)
+	GetPurchaseOrders(form form.GetPurchaseOrders) ([]model.PurchaseOrder, int64, error)
+	GetPurchaseOrderById(id string) (*model.PurchaseOrder, error)
+	UpdatePurchaseOrderById(id string, order model.PurchaseOrder) (*model.PurchaseOrder, error)
+	UpdateStatusById(id string, status string, updatedBy string) (*model.PurchaseOrder, error)
+	ReceiveItems(ctx context.Context, id string, items []form.ReceiveItem, updatedBy string) (*model.PurchaseOrder, error)
+	CreateGoodsReceipt(ctx context.Context, receipt model.GoodsReceipt) (*model.GoodsReceipt, error)
+	GetGoodsReceipts(purchaseOrderId string) ([]model.GoodsReceipt, error)
+	MigrateExpireDates() (int, error)
+}
+
+func NewPurchaseEntity(resource *db.Resource) IPurchase {
+	purchaseRepo := resource.DB.Collection("purchase_orders")
+	receiptRepo := resource.DB.Collection("goods_receipts")
+	var entity IPurchase = &purchaseEntity{
+		purchaseRepo: purchaseRepo,
+		receiptRepo:  receiptRepo,
+	}
+	_, _ = entity.CreateIndex()
+	_, _ = entity.MigrateExpireDates()
+	return entity
+}
+
+func (entity *purchaseEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mod := mongo.IndexModel{
+		Keys: bson.D{{Key: "supplierId", Value: 1}, {Key: "createdDate", Value: -1}},
+	}
+	ind, err := entity.purchaseRepo.Indexes().CreateOne(ctx, mod)
+	if err != nil {
+		return "", err
+	}
+	_, err = entity.receiptRepo.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"purchaseOrderId": 1},
+	})
+	return ind, err
+}
+
+// CreatePurchaseOrder saves a draft order, order carries the number, branch, supplier and lines
+func (entity *purchaseEntity) CreatePurchaseOrder(ctx context.Context, order model.PurchaseOrder) (*model.PurchaseOrder, error) {
+	logrus.Info("CreatePurchaseOrder")
+	order.Id = primitive.NewObjectID()
+	order.Status = constant.DRAFT
+	order.CreatedBy = order.UpdatedBy
+	order.CreatedDate = time.Now()
+	order.UpdatedDate = order.CreatedDate
+	_, err := entity.purchaseRepo.InsertOne(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	order.SetOutstanding()
+	return &order, nil
+}
+
+func (entity *purchaseEntity) GetPurchaseOrders(form form.GetPurchaseOrders) ([]model.PurchaseOrder, int64, error) {
+	logrus.Info("GetPurchaseOrders")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	filter := bson.M{}
+	if form.Status != "" {
+		filter["status"] = form.Status
+	}
+	if form.SupplierId != "" {
+		supplierId, _ := primitive.ObjectIDFromHex(form.SupplierId)
+		filter["supplierId"] = supplierId
+	}
+
+	count, err := entity.purchaseRepo.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	page := form.Page
+	if page < 1 {
+		page = 1
+	}
+	size := form.Size
+	if size < 1 || size > 100 {
+		size = 20
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdDate", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * size).
+		SetLimit(size)
+
+	var items []model.PurchaseOrder
+	cursor, err := entity.purchaseRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	for cursor.Next(ctx) {
+		var data model.PurchaseOrder
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			data.SetOutstanding()
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.PurchaseOrder{}
+	}
+	return items, count, nil
+}
+
+func (entity *purchaseEntity) GetPurchaseOrderById(id string) (*model.PurchaseOrder, error) {
+	logrus.Info("GetPurchaseOrderById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.PurchaseOrder
+	err := entity.purchaseRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	data.SetOutstanding()
+	return &data, nil
+}
+
+// UpdatePurchaseOrderById rewrites the supplier and lines of a draft, fails with ErrStatusInvalid once it was ordered
+func (entity *purchaseEntity) UpdatePurchaseOrderById(id string, order model.PurchaseOrder) (*model.PurchaseOrder, error) {
+	logrus.Info("UpdatePurchaseOrderById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	update := bson.M{"$set": bson.M{
+		"supplierId":   order.SupplierId,
+		"supplierName": order.SupplierName,
+		"items":        order.Items,
+		"total":        order.Total,
+		"note":         order.Note,
+		"expectedDate": order.ExpectedDate,
+		"updatedBy":    order.UpdatedBy,
+		"updatedDate":  time.Now(),
+	}}
+	var data model.PurchaseOrder
+	err := entity.purchaseRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId, "status": constant.DRAFT}, update, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		count, _ := entity.purchaseRepo.CountDocuments(ctx, bson.M{"_id": objId})
+		if count > 0 {
+			return nil, ErrStatusInvalid
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	data.SetOutstanding()
+	return &data, nil
+}
+
+// UpdateStatusById places a draft with the supplier or cancels an order nothing was received against yet
+func (entity *purchaseEntity) UpdateStatusById(id string, status string, updatedBy string) (*model.PurchaseOrder, error) {
+	logrus.Info("UpdateStatusById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	set := bson.M{
+		"status":      status,
+		"updatedBy":   updatedBy,
+		"updatedDate": time.Now(),
+	}
+	if status == constant.ORDERED {
+		set["orderedBy"] = updatedBy
+		set["orderedDate"] = time.Now()
+	}
+	filter := bson.M{"_id": objId, "status": bson.M{"$in": nextStatuses[status]}}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.PurchaseOrder
+	err := entity.purchaseRepo.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		count, _ := entity.purchaseRepo.CountDocuments(ctx, bson.M{"_id": objId})
+		if count > 0 {
+			return nil, ErrStatusInvalid
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	data.SetOutstanding()
+	return &data, nil
+}
+
+// ReceiveItems adds the received quantities to the lines of an ordered purchase order and moves it to partially
+// received or received, fails with ErrOverReceipt when a line would get more than is outstanding
+func (entity *purchaseEntity) ReceiveItems(ctx context.Context, id string, items []form.ReceiveItem, updatedBy string) (*model.PurchaseOrder, error) {
+	logrus.Info("ReceiveItems")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.PurchaseOrder
+	err := entity.purchaseRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Status != constant.ORDERED && data.Status != constant.PARTIALLY_RECEIVED {
+		return nil, ErrStatusInvalid
+	}
+	for _, item := range items {
+		productId, _ := primitive.ObjectIDFromHex(item.ProductId)
+		line := data.GetItem(productId)
+		if line == nil {
+			return nil, ErrItemNotOrdered
+		}
+		line.ReceivedQuantity += item.Quantity
+		if line.ReceivedQuantity > line.Quantity {
+			return nil, ErrOverReceipt
+		}
+	}
+
+	data.Status = data.GetReceivedStatus()
+	set := bson.M{
+		"items":       data.Items,
+		"status":      data.Status,
+		"updatedBy":   updatedBy,
+		"updatedDate": time.Now(),
+	}
+	if data.Status == constant.RECEIVED {
+		set["receivedDate"] = time.Now()
+	}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	filter := bson.M{"_id": objId, "updatedDate": data.UpdatedDate}
+	err = entity.purchaseRepo.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrStatusInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	data.SetOutstanding()
+	return &data, nil
+}
+
+func (entity *purchaseEntity) CreateGoodsReceipt(ctx context.Context, receipt model.GoodsReceipt) (*model.GoodsReceipt, error) {
+	logrus.Info("CreateGoodsReceipt")
+	receipt.Id = primitive.NewObjectID()
+	receipt.Total = 0
+	for _, item := range receipt.Items {
+		receipt.Total += item.CostPrice * float64(item.Quantity)
+	}
+	receipt.Total = utils.RoundPrice(receipt.Total)
+	receipt.CreatedDate = time.Now()
+	_, err := entity.receiptRepo.InsertOne(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (entity *purchaseEntity) GetGoodsReceipts(purchaseOrderId string) ([]model.GoodsReceipt, error) {
+	logrus.Info("GetGoodsReceipts")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(purchaseOrderId)
+	var items []model.GoodsReceipt
+	opts := options.Find().SetSort(bson.D{{Key: "createdDate", Value: 1}})
+	cursor, err := entity.receiptRepo.Find(ctx, bson.M{"purchaseOrderId": objId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.GoodsReceipt
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.GoodsReceipt{}
+	}
+	return items, nil
+}
+
+// MigrateExpireDates turns the expire dates receipts were saved with as text into dates, the same as their lots
+func (entity *purchaseEntity) MigrateExpireDates() (int, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	cursor, err := entity.receiptRepo.Find(ctx, bson.M{"items.expireDate": bson.M{"$type": "string"}})
+	if err != nil {
+		return 0, err
+	}
+	var receipts []struct {
+		Id    primitive.ObjectID `bson:"_id"`
+		Items []bson.M           `bson:"items"`
+	}
+	if err = cursor.All(ctx, &receipts); err != nil {
+		return 0, err
+	}
+	for _, receipt := range receipts {
+		for index, item := range receipt.Items {
+			value, ok := item["expireDate"].(string)
+			if !ok {
+				continue
+			}
+			update, ok := model2.GetExpireDateUpdate(fmt.Sprintf("items.%d.expireDate", index), value)
+			if !ok {
+				logrus.Warnf("goods receipt %s expire date %q is unreadable, kept as expireDateText", receipt.Id.Hex(), value)
+			}
+			_, err = entity.receiptRepo.UpdateOne(ctx, bson.M{"_id": receipt.Id}, update)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(receipts), nil
+}
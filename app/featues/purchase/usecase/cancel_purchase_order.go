@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/purchase/repository"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// CancelPurchaseOrder drops a draft or an order nothing was received against
+func CancelPurchaseOrder(purchaseEntity repository.IPurchase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		purchaseOrderId := ctx.Param("purchaseOrderId")
+		result, err := purchaseEntity.UpdateStatusById(purchaseOrderId, constant.CANCELLED, ctx.GetString("UserId"))
+		if errors.Is(err, repository.ErrStatusInvalid) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	repository2 "devper/app/featues/counter/repository"
+	repository3 "devper/app/featues/product/repository"
+	"devper/app/featues/purchase/form"
+	"devper/app/featues/purchase/model"
+	"devper/app/featues/purchase/repository"
+	repository4 "devper/app/featues/supplier/repository"
+	"devper/db"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+// CreatePurchaseOrder drafts an order to a supplier, it is placed with the supplier separately
+func CreatePurchaseOrder(
+	transaction db.ITransaction,
+	purchaseEntity repository.IPurchase,
+	productEntity repository3.IProduct,
+	supplierEntity repository4.ISupplier,
+	counterEntity repository2.ICounter,
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.PurchaseOrder{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		order, err := getPurchaseOrder(supplierEntity, productEntity, request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		branch := utils.GetBranchCode()
+		var result *model.PurchaseOrder
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			number, err := counterEntity.NextSequence(sessionCtx, "purchase-order-"+branch)
+			if err != nil {
+				return err
+			}
+			order.Number = number
+			order.Branch = branch
+			result, err = purchaseEntity.CreatePurchaseOrder(sessionCtx, *order)
+			return err
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
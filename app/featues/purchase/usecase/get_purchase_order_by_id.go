@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"devper/app/featues/purchase/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetPurchaseOrderById(purchaseEntity repository.IPurchase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		purchaseOrderId := ctx.Param("purchaseOrderId")
+		result, err := purchaseEntity.GetPurchaseOrderById(purchaseOrderId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"devper/app/featues/purchase/form"
+	"devper/app/featues/purchase/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func GetPurchaseOrders(purchaseEntity repository.IPurchase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetPurchaseOrders{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, total, err := purchaseEntity.GetPurchaseOrders(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
+		ctx.JSON(http.StatusOK, result)
+	}
+}
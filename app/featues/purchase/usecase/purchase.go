@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/purchase/form"
+	"devper/app/featues/purchase/model"
+	"devper/app/featues/purchase/repository"
+	repository3 "devper/app/featues/supplier/repository"
+)
+
+// getPurchaseOrder fills the supplier and the lines of the order from the form, each line named after its product
+// and carrying the supplier's own code for it when the product is linked to the supplier
+func getPurchaseOrder(supplierEntity repository3.ISupplier, productEntity repository2.IProduct, request form.PurchaseOrder) (*model.PurchaseOrder, error) {
+	supplier, err := supplierEntity.GetSupplierById(request.SupplierId)
+	if err != nil || supplier.Status != constant.ACTIVE {
+		return nil, repository.ErrSupplierNotFound
+	}
+	links, err := supplierEntity.GetSupplierProducts(request.SupplierId)
+	if err != nil {
+		return nil, err
+	}
+
+	order := model.PurchaseOrder{
+		SupplierId:   supplier.Id,
+		SupplierName: supplier.Name,
+		Items:        []model.PurchaseOrderItem{},
+		Note:         request.Note,
+		ExpectedDate: request.ExpectedDate,
+		UpdatedBy:    request.UpdatedBy,
+	}
+	for _, item := range request.Items {
+		product, err := productEntity.GetProductById(item.ProductId)
+		if err != nil {
+			return nil, repository.ErrProductNotFound
+		}
+		if order.GetItem(product.Id) != nil {
+			return nil, repository.ErrDuplicateItem
+		}
+		line := model.PurchaseOrderItem{
+			ProductId:    product.Id,
+			Name:         product.Name,
+			Unit:         product.Unit,
+			SerialNumber: product.SerialNumber,
+			Quantity:     item.Quantity,
+			CostPrice:    item.CostPrice,
+			Total:        utils.RoundPrice(item.CostPrice * float64(item.Quantity)),
+		}
+		for _, link := range links {
+			if link.ProductId == line.ProductId {
+				line.SupplierSku = link.SupplierSku
+			}
+		}
+		order.Items = append(order.Items, line)
+		order.Total += line.Total
+	}
+	order.Total = utils.RoundPrice(order.Total)
+	return &order, nil
+}
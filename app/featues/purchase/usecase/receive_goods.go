@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	repository3 "devper/app/featues/counter/repository"
+	model2 "devper/app/featues/product/model"
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/purchase/form"
+	"devper/app/featues/purchase/model"
+	"devper/app/featues/purchase/repository"
+	repository4 "devper/app/featues/supplier/repository"
+	"devper/db"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ReceiveGoods books a delivery against an ordered purchase order, every line becomes a product lot traced to the
+// supplier and the received quantities move the order towards received, all of it or none
+func ReceiveGoods(
+	transaction db.ITransaction,
+	purchaseEntity repository.IPurchase,
+	productEntity repository2.IProduct,
+	supplierEntity repository4.ISupplier,
+	counterEntity repository3.ICounter,
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		purchaseOrderId := ctx.Param("purchaseOrderId")
+		request := form.ReceiveGoods{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CreatedBy = ctx.GetString("UserId")
+
+		branch := utils.GetBranchCode()
+		var result *model.GoodsReceipt
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			order, err := purchaseEntity.ReceiveItems(sessionCtx, purchaseOrderId, request.Items, request.CreatedBy)
+			if err != nil {
+				return err
+			}
+			number, err := counterEntity.NextSequence(sessionCtx, "goods-receipt-"+branch)
+			if err != nil {
+				return err
+			}
+			movement := model2.StockMovement{
+				RefType:   constant.RefPurchase,
+				RefId:     order.Id,
+				RefNumber: strconv.FormatInt(order.Number, 10),
+				Note:      request.Note,
+				CreatedBy: request.CreatedBy,
+			}
+			receipt := model.GoodsReceipt{
+				Number:              number,
+				Branch:              branch,
+				PurchaseOrderId:     order.Id,
+				PurchaseOrderNumber: order.Number,
+				SupplierId:          order.SupplierId,
+				Note:                request.Note,
+				CreatedBy:           request.CreatedBy,
+			}
+			for _, item := range request.Items {
+				productId, _ := primitive.ObjectIDFromHex(item.ProductId)
+				line := order.GetItem(productId)
+				costPrice := line.CostPrice
+				if item.CostPrice != nil {
+					costPrice = *item.CostPrice
+				}
+				expireDate, ok := model2.ParseExpireDate(item.ExpireDate)
+				if !ok && strings.TrimSpace(item.ExpireDate) != "" {
+					return repository2.ErrExpireDateInvalid
+				}
+				lot, err := productEntity.ReceiveLot(sessionCtx, model2.ProductLot{
+					ProductId:  productId,
+					LotNumber:  item.LotNumber,
+					ExpireDate: expireDate,
+					SupplierId: order.SupplierId,
+					CostPrice:  costPrice,
+					Quantity:   item.Quantity,
+					CreatedBy:  request.CreatedBy,
+				}, movement)
+				if err != nil {
+					return err
+				}
+				err = supplierEntity.UpdateLastPurchase(sessionCtx, order.SupplierId, productId, costPrice, request.CreatedBy)
+				if err != nil {
+					return err
+				}
+				receipt.Items = append(receipt.Items, model.GoodsReceiptItem{
+					ProductId:  productId,
+					Name:       line.Name,
+					LotId:      lot.Id,
+					LotNumber:  lot.LotNumber,
+					ExpireDate: lot.ExpireDate,
+					CostPrice:  lot.CostPrice,
+					Quantity:   lot.Quantity,
+				})
+			}
+			result, err = purchaseEntity.CreateGoodsReceipt(sessionCtx, receipt)
+			return err
+		})
+		if errors.Is(err, repository.ErrStatusInvalid) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/purchase/form"
+	"devper/app/featues/purchase/repository"
+	repository3 "devper/app/featues/supplier/repository"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func UpdatePurchaseOrderById(purchaseEntity repository.IPurchase, productEntity repository2.IProduct, supplierEntity repository3.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		purchaseOrderId := ctx.Param("purchaseOrderId")
+		request := form.PurchaseOrder{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		order, err := getPurchaseOrder(supplierEntity, productEntity, request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := purchaseEntity.UpdatePurchaseOrderById(purchaseOrderId, *order)
+		if errors.Is(err, repository.ErrStatusInvalid) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
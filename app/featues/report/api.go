@@ -0,0 +1,64 @@
+package report
+
+import (
+	"devper/app/core/constant"
+	repository3 "devper/app/featues/counter/repository"
+	"devper/app/featues/report/repository"
+	"devper/app/featues/report/usecase"
+	repository2 "devper/app/featues/user/repository"
+	"devper/db"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplyReportAPI(
+	app *gin.RouterGroup,
+	transaction db.ITransaction,
+	reportEntity repository.IReport,
+	counterEntity repository3.ICounter,
+	userEntity repository2.IUser,
+) {
+	reportRoute := app.Group("report")
+
+	reportRoute.GET("/daily",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetDailySummary(reportEntity),
+	)
+
+	reportRoute.POST("/close",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CloseDay(transaction, reportEntity, counterEntity),
+	)
+
+	reportRoute.GET("/z",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetZReports(reportEntity),
+	)
+
+	reportRoute.GET("/z/:date",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetZReportByDate(reportEntity),
+	)
+
+	reportRoute.GET("/product",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetProductAnalytics(reportEntity),
+	)
+
+	reportRoute.GET("/category",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetCategoryAnalytics(reportEntity),
+	)
+
+	reportRoute.GET("/time",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetTimeAnalytics(reportEntity),
+	)
+}
@@ -0,0 +1,14 @@
+package form
+
+type GetAnalytics struct {
+	StartDate string `form:"startDate" binding:"required,datetime=2006-01-02"`
+	EndDate   string `form:"endDate" binding:"required,datetime=2006-01-02"`
+}
+
+type GetProductAnalytics struct {
+	StartDate string `form:"startDate" binding:"required,datetime=2006-01-02"`
+	EndDate   string `form:"endDate" binding:"required,datetime=2006-01-02"`
+	Sort      string `form:"sort" binding:"omitempty,oneof=units revenue margin"`
+	Limit     int64  `form:"limit"`
+	Category  string `form:"category"`
+}
@@ -0,0 +1,17 @@
+package form
+
+type GetDailySummary struct {
+	Date    string `form:"date" binding:"omitempty,datetime=2006-01-02"`
+	ShiftId string `form:"shiftId"`
+}
+
+type CloseDay struct {
+	Date      string `json:"date" binding:"omitempty,datetime=2006-01-02"`
+	Notify    bool   `json:"notify"`
+	CreatedBy string
+}
+
+type GetZReports struct {
+	StartDate string `form:"startDate" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   string `form:"endDate" binding:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"devper/app/core/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Period struct {
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+// Sales is what the sold units of a group brought in, net of refunds, revenue includes VAT and margin excludes it
+type Sales struct {
+	Units   int     `bson:"units" json:"units"`
+	Revenue float64 `bson:"revenue" json:"revenue"`
+	Cost    float64 `bson:"cost" json:"cost"`
+	Margin  float64 `bson:"margin" json:"margin"`
+}
+
+func (sales *Sales) Round() {
+	sales.Revenue = utils.RoundPrice(sales.Revenue)
+	sales.Cost = utils.RoundPrice(sales.Cost)
+	sales.Margin = utils.RoundPrice(sales.Margin)
+}
+
+// Comparison puts a group's sales beside the previous period, changes are percentages and zero when there
+// was nothing to compare with
+type Comparison struct {
+	Sales         `bson:",inline"`
+	Previous      Sales   `json:"previous"`
+	UnitsChange   float64 `json:"unitsChange"`
+	RevenueChange float64 `json:"revenueChange"`
+	MarginChange  float64 `json:"marginChange"`
+}
+
+func (comparison *Comparison) Compare(previous Sales) {
+	comparison.Round()
+	previous.Round()
+	comparison.Previous = previous
+	comparison.UnitsChange = getChange(float64(comparison.Units), float64(previous.Units))
+	comparison.RevenueChange = getChange(comparison.Revenue, previous.Revenue)
+	comparison.MarginChange = getChange(comparison.Margin, previous.Margin)
+}
+
+func getChange(current float64, previous float64) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return utils.RoundPrice((current - previous) / previous * 100)
+}
+
+type ProductSales struct {
+	ProductId  primitive.ObjectID `bson:"_id" json:"productId"`
+	Name       string             `bson:"name" json:"name"`
+	Category   string             `bson:"category" json:"category"`
+	Unit       string             `bson:"unit" json:"unit"`
+	Rank       int                `json:"rank"`
+	Comparison `bson:",inline"`
+}
+
+type CategorySales struct {
+	Category   string `bson:"_id" json:"category"`
+	Comparison `bson:",inline"`
+}
+
+// TimeSales is the sales of one hour of the day (0-23) or one day of the week (1 Sunday to 7 Saturday)
+type TimeSales struct {
+	Slot       int `bson:"_id" json:"slot"`
+	Orders     int `bson:"orders" json:"orders"`
+	Comparison `bson:",inline"`
+}
+
+type ProductAnalytics struct {
+	Period         Period         `json:"period"`
+	PreviousPeriod Period         `json:"previousPeriod"`
+	Total          Comparison     `json:"total"`
+	Products       []ProductSales `json:"products"`
+}
+
+type CategoryAnalytics struct {
+	Period         Period          `json:"period"`
+	PreviousPeriod Period          `json:"previousPeriod"`
+	Total          Comparison      `json:"total"`
+	Categories     []CategorySales `json:"categories"`
+}
+
+type TimeAnalytics struct {
+	Period         Period      `json:"period"`
+	PreviousPeriod Period      `json:"previousPeriod"`
+	Hours          []TimeSales `json:"hours"`
+	Days           []TimeSales `json:"days"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"devper/app/core/utils"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type DailySummary struct {
+	StartDate     time.Time        `bson:"startDate" json:"startDate"`
+	EndDate       time.Time        `bson:"endDate" json:"endDate"`
+	Orders        int              `bson:"orders" json:"orders"`
+	GrossSales    float64          `bson:"grossSales" json:"grossSales"`
+	Discount      float64          `bson:"discount" json:"discount"`
+	Sales         float64          `bson:"sales" json:"sales"`
+	Voids         int              `bson:"voids" json:"voids"`
+	VoidTotal     float64          `bson:"voidTotal" json:"voidTotal"`
+	Refunds       int              `bson:"refunds" json:"refunds"`
+	RefundTotal   float64          `bson:"refundTotal" json:"refundTotal"`
+	NetSales      float64          `bson:"netSales" json:"netSales"`
+	VatAmount     float64          `bson:"vatAmount" json:"vatAmount"`
+	Cogs          float64          `bson:"cogs" json:"cogs"`
+	GrossMargin   float64          `bson:"grossMargin" json:"grossMargin"`
+	MarginPercent float64          `bson:"marginPercent" json:"marginPercent"`
+	Payments      []PaymentSummary `bson:"payments" json:"payments"`
+}
+
+type PaymentSummary struct {
+	Type    string  `bson:"_id" json:"type"`
+	Count   int     `bson:"count" json:"count"`
+	Amount  float64 `bson:"amount" json:"amount"`
+	Change  float64 `bson:"change" json:"change"`
+	Refund  float64 `bson:"refund" json:"refund"`
+	Pending float64 `bson:"pending" json:"pending"`
+	Total   float64 `bson:"total" json:"total"`
+}
+
+type ZReport struct {
+	Id          primitive.ObjectID `bson:"_id" json:"id"`
+	Number      int64              `bson:"number" json:"number"`
+	Branch      string             `bson:"branch" json:"branch"`
+	Date        string             `bson:"date" json:"date"`
+	Summary     DailySummary       `bson:"summary" json:"summary"`
+	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
+}
+
+// GetMessage builds the Thai summary pushed to LINE at close
+func (summary DailySummary) GetMessage() string {
+	message := fmt.Sprintf("ยอดขาย %d รายการ %.2f บาท\n", summary.Orders, summary.GrossSales)
+	message += fmt.Sprintf("ส่วนลด %.2f บาท\n", summary.Discount)
+	message += fmt.Sprintf("ยกเลิก %d รายการ %.2f บาท\n", summary.Voids, summary.VoidTotal)
+	message += fmt.Sprintf("คืนสินค้า %d รายการ %.2f บาท\n", summary.Refunds, summary.RefundTotal)
+	message += fmt.Sprintf("ยอดสุทธิ %.2f บาท\n", summary.NetSales)
+	message += fmt.Sprintf("ต้นทุน %.2f บาท\n", summary.Cogs)
+	message += fmt.Sprintf("กำไรขั้นต้น %.2f บาท (%.2f%%)\n", summary.GrossMargin, summary.MarginPercent)
+	for _, payment := range summary.Payments {
+		message += fmt.Sprintf("\n%s %d รายการ %.2f บาท", payment.Type, payment.Count, payment.Total)
+	}
+	return message
+}
+
+// GetMessage builds the Thai Z report pushed to LINE at close
+func (report ZReport) GetMessage() string {
+	header := fmt.Sprintf("ปิดยอดวันที่ %s Z%04d\n", report.Date, report.Number)
+	if report.Branch != "" {
+		header += "สาขา " + report.Branch + "\n"
+	}
+	return header + "\n" + report.Summary.GetMessage() + "\n\nปิดเมื่อ " + utils.ToFormat(report.CreatedDate)
+}
@@ -0,0 +1,528 @@
+package repository
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/report/form"
+	"devper/app/featues/report/model"
+	"devper/db"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reportEntity struct {
+	orderRepo     *mongo.Collection
+	orderItemRepo *mongo.Collection
+	paymentRepo   *mongo.Collection
+	refundRepo    *mongo.Collection
+	zReportRepo   *mongo.Collection
+}
+
+type IReport interface {
+	CreateIndex() (string, error)
+	GetDailySummary(startDate time.Time, endDate time.Time, shiftId string) (*model.DailySummary, error)
+	CreateZReport(ctx context.Context, report model.ZReport) (*model.ZReport, error)
+	GetZReportByDate(branch string, date string) (*model.ZReport, error)
+	GetZReports(branch string, startDate string, endDate string) ([]model.ZReport, error)
+	GetSalesTotal(startDate time.Time, endDate time.Time) (*model.Sales, error)
+	GetProductSales(startDate time.Time, endDate time.Time, form form.GetProductAnalytics, productIds []primitive.ObjectID) ([]model.ProductSales, error)
+	GetCategorySales(startDate time.Time, endDate time.Time) ([]model.CategorySales, error)
+	GetTimeSales(startDate time.Time, endDate time.Time, slot string) ([]model.TimeSales, error)
+}
+
+func NewReportEntity(resource *db.Resource) IReport {
+	orderRepo := resource.DB.Collection("orders")
+	orderItemRepo := resource.DB.Collection("order_items")
+	paymentRepo := resource.DB.Collection("payments")
+	refundRepo := resource.DB.Collection("refunds")
+	zReportRepo := resource.DB.Collection("z_reports")
+	var entity IReport = &reportEntity{
+		orderRepo:     orderRepo,
+		orderItemRepo: orderItemRepo,
+		paymentRepo:   paymentRepo,
+		refundRepo:    refundRepo,
+		zReportRepo:   zReportRepo,
+	}
+	_, _ = entity.CreateIndex()
+	return entity
+}
+
+func (entity *reportEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mod := mongo.IndexModel{
+		Keys:    bson.D{{Key: "branch", Value: 1}, {Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	ind, err := entity.zReportRepo.Indexes().CreateOne(ctx, mod)
+	return ind, err
+}
+
+// GetDailySummary sums the sales of the orders taken in the window, voids and refunds count on the day they
+// happened and payments on the day they were taken, the same way the VAT report does. A shiftId keeps only
+// what was taken in that cashier shift
+func (entity *reportEntity) GetDailySummary(startDate time.Time, endDate time.Time, shiftId string) (*model.DailySummary, error) {
+	logrus.Info("GetDailySummary")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	createdDate := bson.M{
+		"$gte": startDate,
+		"$lt":  endDate,
+	}
+	summary := model.DailySummary{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	match := func(filter bson.M) bson.M {
+		if shiftId != "" {
+			objId, _ := primitive.ObjectIDFromHex(shiftId)
+			filter["shiftId"] = objId
+		}
+		return filter
+	}
+
+	var sales []struct {
+		Orders     int     `bson:"orders"`
+		GrossSales float64 `bson:"grossSales"`
+		Discount   float64 `bson:"discount"`
+		Sales      float64 `bson:"sales"`
+		VatAmount  float64 `bson:"vatAmount"`
+		Cost       float64 `bson:"cost"`
+	}
+	cursor, err := entity.orderRepo.Aggregate(ctx, []bson.M{
+		{
+			"$match": match(bson.M{
+				"createdDate": createdDate,
+				"status":      bson.M{"$ne": constant.OPEN},
+			}),
+		},
+		{
+			"$group": bson.M{
+				"_id":    "",
+				"orders": bson.M{"$sum": 1},
+				"grossSales": bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$gt": bson.A{"$grossTotal", 0}}, "$grossTotal", "$total",
+				}}},
+				"discount":  bson.M{"$sum": bson.M{"$ifNull": bson.A{"$discount", 0}}},
+				"sales":     bson.M{"$sum": "$total"},
+				"vatAmount": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$vatAmount", 0}}},
+				"cost":      bson.M{"$sum": "$totalCost"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &sales); err != nil {
+		return nil, err
+	}
+	if len(sales) > 0 {
+		summary.Orders = sales[0].Orders
+		summary.GrossSales = sales[0].GrossSales
+		summary.Discount = sales[0].Discount
+		summary.Sales = sales[0].Sales
+		summary.VatAmount = sales[0].VatAmount
+		summary.Cogs = sales[0].Cost
+	}
+
+	var refunds []struct {
+		Voided    bool    `bson:"_id"`
+		Count     int     `bson:"count"`
+		Total     float64 `bson:"total"`
+		VatAmount float64 `bson:"vatAmount"`
+		Cost      float64 `bson:"cost"`
+	}
+	cursor, err = entity.refundRepo.Aggregate(ctx, []bson.M{
+		{
+			"$match": match(bson.M{
+				"createdDate": createdDate,
+			}),
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "orders",
+				"localField":   "orderId",
+				"foreignField": "_id",
+				"as":           "order",
+			},
+		},
+		{"$unwind": "$order"},
+		{"$unwind": "$items"},
+		{
+			"$lookup": bson.M{
+				"from":         "order_items",
+				"localField":   "items.itemId",
+				"foreignField": "_id",
+				"as":           "orderItem",
+			},
+		},
+		{"$unwind": bson.M{"path": "$orderItem", "preserveNullAndEmptyArrays": true}},
+		{
+			"$group": bson.M{
+				"_id":       "$_id",
+				"voided":    bson.M{"$first": bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$type", "$order.status"}}, constant.VOIDED}}},
+				"total":     bson.M{"$sum": "$items.amount"},
+				"vatAmount": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$items.vatAmount", 0}}},
+				"cost": bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$gt": bson.A{"$orderItem.quantity", 0}},
+					bson.M{"$divide": bson.A{
+						bson.M{"$multiply": bson.A{"$orderItem.costPrice", "$items.quantity"}},
+						"$orderItem.quantity",
+					}},
+					0,
+				}}},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":       "$voided",
+				"count":     bson.M{"$sum": 1},
+				"total":     bson.M{"$sum": "$total"},
+				"vatAmount": bson.M{"$sum": "$vatAmount"},
+				"cost":      bson.M{"$sum": "$cost"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &refunds); err != nil {
+		return nil, err
+	}
+	for _, refund := range refunds {
+		if refund.Voided {
+			summary.Voids = refund.Count
+			summary.VoidTotal = refund.Total
+		} else {
+			summary.Refunds = refund.Count
+			summary.RefundTotal = refund.Total
+		}
+		summary.VatAmount -= refund.VatAmount
+		summary.Cogs -= refund.Cost
+	}
+
+	isStatus := func(statuses ...string) bson.M {
+		return bson.M{"$in": bson.A{"$status", statuses}}
+	}
+	var payments []model.PaymentSummary
+	cursor, err = entity.paymentRepo.Aggregate(ctx, []bson.M{
+		{
+			"$match": match(bson.M{
+				"createdDate": createdDate,
+				"type":        bson.M{"$ne": ""},
+			}),
+		},
+		{
+			"$group": bson.M{
+				"_id":     "$type",
+				"count":   bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.ACTIVE, constant.PAID), 1, 0}}},
+				"amount":  bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.ACTIVE, constant.PAID), "$amount", 0}}},
+				"change":  bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.ACTIVE, constant.PAID), "$change", 0}}},
+				"refund":  bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.REFUNDED), "$total", 0}}},
+				"pending": bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.PENDING), "$total", 0}}},
+				"total":   bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.ACTIVE, constant.PAID, constant.REFUNDED), "$total", 0}}},
+			},
+		},
+		{"$sort": bson.M{"_id": 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+	for index := range payments {
+		payment := &payments[index]
+		payment.Amount = utils.RoundPrice(payment.Amount)
+		payment.Change = utils.RoundPrice(payment.Change)
+		payment.Refund = utils.RoundPrice(payment.Refund)
+		payment.Pending = utils.RoundPrice(payment.Pending)
+		payment.Total = utils.RoundPrice(payment.Total)
+	}
+	if payments == nil {
+		payments = []model.PaymentSummary{}
+	}
+	summary.Payments = payments
+
+	summary.GrossSales = utils.RoundPrice(summary.GrossSales)
+	summary.Discount = utils.RoundPrice(summary.Discount)
+	summary.Sales = utils.RoundPrice(summary.Sales)
+	summary.VoidTotal = utils.RoundPrice(summary.VoidTotal)
+	summary.RefundTotal = utils.RoundPrice(summary.RefundTotal)
+	summary.NetSales = utils.RoundPrice(summary.Sales - summary.VoidTotal - summary.RefundTotal)
+	summary.VatAmount = utils.RoundPrice(summary.VatAmount)
+	summary.Cogs = utils.RoundPrice(summary.Cogs)
+	summary.GrossMargin = utils.RoundPrice(summary.NetSales - summary.VatAmount - summary.Cogs)
+	if net := summary.NetSales - summary.VatAmount; net > 0 {
+		summary.MarginPercent = utils.RoundPrice(summary.GrossMargin / net * 100)
+	}
+	return &summary, nil
+}
+
+func (entity *reportEntity) CreateZReport(ctx context.Context, report model.ZReport) (*model.ZReport, error) {
+	logrus.Info("CreateZReport")
+	_, err := entity.zReportRepo.InsertOne(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (entity *reportEntity) GetZReportByDate(branch string, date string) (*model.ZReport, error) {
+	logrus.Info("GetZReportByDate")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	var data model.ZReport
+	err := entity.zReportRepo.FindOne(ctx, bson.M{"branch": branch, "date": date}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *reportEntity) GetZReports(branch string, startDate string, endDate string) ([]model.ZReport, error) {
+	logrus.Info("GetZReports")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	filter := bson.M{"branch": branch}
+	date := bson.M{}
+	if startDate != "" {
+		date["$gte"] = startDate
+	}
+	if endDate != "" {
+		date["$lte"] = endDate
+	}
+	if len(date) > 0 {
+		filter["date"] = date
+	}
+	var items []model.ZReport
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}})
+	cursor, err := entity.zReportRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.ZReport
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.ZReport{}
+	}
+	return items, nil
+}
+
+// getSalesPipeline takes the items sold in the window from paid orders and works out each item's share
+// that was not refunded, the fields summed by salesGroup
+func getSalesPipeline(startDate time.Time, endDate time.Time) []bson.M {
+	ratio := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{"$quantity", 0}},
+		bson.M{"$divide": bson.A{
+			bson.M{"$subtract": bson.A{"$quantity", bson.M{"$ifNull": bson.A{"$refundedQuantity", 0}}}},
+			"$quantity",
+		}},
+		0,
+	}}
+	return []bson.M{
+		{
+			"$match": bson.M{
+				"createdDate": bson.M{
+					"$gte": startDate,
+					"$lt":  endDate,
+				},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "orders",
+				"localField":   "orderId",
+				"foreignField": "_id",
+				"as":           "order",
+			},
+		},
+		{"$unwind": "$order"},
+		{
+			"$match": bson.M{
+				"order.status": bson.M{"$nin": bson.A{constant.OPEN, constant.VOIDED}},
+			},
+		},
+		{"$addFields": bson.M{"ratio": ratio}},
+		{
+			"$addFields": bson.M{
+				"units":   bson.M{"$subtract": bson.A{"$quantity", bson.M{"$ifNull": bson.A{"$refundedQuantity", 0}}}},
+				"revenue": bson.M{"$multiply": bson.A{"$price", "$ratio"}},
+				"cost":    bson.M{"$multiply": bson.A{"$costPrice", "$ratio"}},
+				"margin": bson.M{"$multiply": bson.A{
+					bson.M{"$subtract": bson.A{"$price", bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$vatAmount", 0}}, "$costPrice"}}}},
+					"$ratio",
+				}},
+			},
+		},
+	}
+}
+
+func salesGroup(id interface{}) bson.M {
+	return bson.M{
+		"_id":     id,
+		"units":   bson.M{"$sum": "$units"},
+		"revenue": bson.M{"$sum": "$revenue"},
+		"cost":    bson.M{"$sum": "$cost"},
+		"margin":  bson.M{"$sum": "$margin"},
+	}
+}
+
+func (entity *reportEntity) GetSalesTotal(startDate time.Time, endDate time.Time) (*model.Sales, error) {
+	logrus.Info("GetSalesTotal")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	pipeline := append(getSalesPipeline(startDate, endDate), bson.M{"$group": salesGroup("")})
+	var result []model.Sales
+	cursor, err := entity.orderItemRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return &model.Sales{}, nil
+	}
+	return &result[0], nil
+}
+
+var productSortFields = map[string]string{
+	"units":   "units",
+	"revenue": "revenue",
+	"margin":  "margin",
+}
+
+// GetProductSales ranks the products sold in the window, only the given products when productIds is not nil
+func (entity *reportEntity) GetProductSales(startDate time.Time, endDate time.Time, form form.GetProductAnalytics, productIds []primitive.ObjectID) ([]model.ProductSales, error) {
+	logrus.Info("GetProductSales")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	pipeline := getSalesPipeline(startDate, endDate)
+	if productIds != nil {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"productId": bson.M{"$in": productIds}}})
+	}
+	pipeline = append(pipeline,
+		bson.M{"$group": salesGroup("$productId")},
+		bson.M{
+			"$lookup": bson.M{
+				"from":         "products",
+				"localField":   "_id",
+				"foreignField": "_id",
+				"as":           "product",
+			},
+		},
+		bson.M{"$unwind": bson.M{"path": "$product", "preserveNullAndEmptyArrays": true}},
+		bson.M{
+			"$addFields": bson.M{
+				"name":     "$product.name",
+				"category": "$product.category",
+				"unit":     "$product.unit",
+			},
+		},
+		bson.M{"$project": bson.M{"product": 0}},
+	)
+	if form.Category != "" {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"category": form.Category}})
+	}
+	sort, ok := productSortFields[form.Sort]
+	if !ok {
+		sort = "revenue"
+	}
+	pipeline = append(pipeline, bson.M{"$sort": bson.D{{Key: sort, Value: -1}, {Key: "_id", Value: 1}}})
+	if productIds == nil {
+		limit := form.Limit
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+		pipeline = append(pipeline, bson.M{"$limit": limit})
+	}
+
+	var items []model.ProductSales
+	cursor, err := entity.orderItemRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	for index := range items {
+		items[index].Rank = index + 1
+	}
+	if items == nil {
+		items = []model.ProductSales{}
+	}
+	return items, nil
+}
+
+// GetCategorySales sums the sales by the current category of each product, by revenue
+func (entity *reportEntity) GetCategorySales(startDate time.Time, endDate time.Time) ([]model.CategorySales, error) {
+	logrus.Info("GetCategorySales")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	pipeline := append(getSalesPipeline(startDate, endDate),
+		bson.M{
+			"$lookup": bson.M{
+				"from":         "products",
+				"localField":   "productId",
+				"foreignField": "_id",
+				"as":           "product",
+			},
+		},
+		bson.M{"$unwind": bson.M{"path": "$product", "preserveNullAndEmptyArrays": true}},
+		bson.M{"$group": salesGroup(bson.M{"$ifNull": bson.A{"$product.category", ""}})},
+		bson.M{"$sort": bson.D{{Key: "revenue", Value: -1}, {Key: "_id", Value: 1}}},
+	)
+	var items []model.CategorySales
+	cursor, err := entity.orderItemRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []model.CategorySales{}
+	}
+	return items, nil
+}
+
+// GetTimeSales sums the sales by the Bangkok hour of the day or day of the week the order was taken,
+// slot is $hour or $dayOfWeek
+func (entity *reportEntity) GetTimeSales(startDate time.Time, endDate time.Time, slot string) ([]model.TimeSales, error) {
+	logrus.Info("GetTimeSales")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	group := salesGroup(bson.M{slot: bson.M{"date": "$order.createdDate", "timezone": "Asia/Bangkok"}})
+	group["orderIds"] = bson.M{"$addToSet": "$orderId"}
+	pipeline := append(getSalesPipeline(startDate, endDate),
+		bson.M{"$group": group},
+		bson.M{"$addFields": bson.M{"orders": bson.M{"$size": "$orderIds"}}},
+		bson.M{"$project": bson.M{"orderIds": 0}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	)
+	var items []model.TimeSales
+	cursor, err := entity.orderItemRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []model.TimeSales{}
+	}
+	return items, nil
+}
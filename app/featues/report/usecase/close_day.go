@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	repository2 "devper/app/featues/counter/repository"
+	"devper/app/featues/report/form"
+	"devper/app/featues/report/model"
+	"devper/app/featues/report/repository"
+	"devper/db"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"time"
+)
+
+// CloseDay freezes the daily summary as the numbered Z report of the branch, a day closes once
+func CloseDay(transaction db.ITransaction, reportEntity repository.IReport, counterEntity repository2.ICounter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.CloseDay{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CreatedBy = ctx.GetString("UserId")
+		date, startDate, endDate, err := getDay(request.Date)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		branch := utils.GetBranchCode()
+		if _, err := reportEntity.GetZReportByDate(branch, date); err == nil {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "day " + date + " is already closed"})
+			return
+		}
+		summary, err := reportEntity.GetDailySummary(startDate, endDate, "")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var result *model.ZReport
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			number, err := counterEntity.NextSequence(sessionCtx, "z-report-"+branch)
+			if err != nil {
+				return err
+			}
+			report, err := reportEntity.CreateZReport(sessionCtx, model.ZReport{
+				Id:          primitive.NewObjectID(),
+				Number:      number,
+				Branch:      branch,
+				Date:        date,
+				Summary:     *summary,
+				CreatedBy:   request.CreatedBy,
+				CreatedDate: time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+			result = report
+			return nil
+		})
+		if mongo.IsDuplicateKeyError(err) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "day " + date + " is already closed"})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if request.Notify {
+			_, _ = utils.NotifyMassage(result.GetMessage())
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"devper/app/featues/report/model"
+	"errors"
+	"time"
+)
+
+const dayFormat = "2006-01-02"
+
+// getDay is the Bangkok day of date, today when empty
+func getDay(date string) (string, time.Time, time.Time, error) {
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	if date == "" {
+		date = time.Now().In(location).Format(dayFormat)
+	}
+	startDate, err := time.ParseInLocation(dayFormat, date, location)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, err
+	}
+	return date, startDate, startDate.AddDate(0, 0, 1), nil
+}
+
+// getPeriod spans the Bangkok days from startDate through endDate, the previous period is as many days right before it
+func getPeriod(startDate string, endDate string) (model.Period, model.Period, error) {
+	_, start, _, err := getDay(startDate)
+	if err != nil {
+		return model.Period{}, model.Period{}, err
+	}
+	_, _, end, err := getDay(endDate)
+	if err != nil {
+		return model.Period{}, model.Period{}, err
+	}
+	if !end.After(start) {
+		return model.Period{}, model.Period{}, errors.New("endDate is before startDate")
+	}
+	days := int(end.Sub(start).Hours() / 24)
+	period := model.Period{StartDate: start, EndDate: end}
+	previous := model.Period{StartDate: start.AddDate(0, 0, -days), EndDate: start}
+	return period, previous, nil
+}
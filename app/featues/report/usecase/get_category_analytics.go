@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"devper/app/featues/report/form"
+	"devper/app/featues/report/model"
+	"devper/app/featues/report/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetCategoryAnalytics(reportEntity repository.IReport) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetAnalytics{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		period, previousPeriod, err := getPeriod(request.StartDate, request.EndDate)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		categories, err := reportEntity.GetCategorySales(period.StartDate, period.EndDate)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		previousCategories, err := reportEntity.GetCategorySales(previousPeriod.StartDate, previousPeriod.EndDate)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		previous := map[string]model.Sales{}
+		for _, category := range previousCategories {
+			previous[category.Category] = category.Sales
+		}
+		for index := range categories {
+			categories[index].Compare(previous[categories[index].Category])
+		}
+		total, err := getTotal(reportEntity, period, previousPeriod)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, model.CategoryAnalytics{
+			Period:         period,
+			PreviousPeriod: previousPeriod,
+			Total:          *total,
+			Categories:     categories,
+		})
+	}
+}
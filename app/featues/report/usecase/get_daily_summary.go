@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"devper/app/featues/report/form"
+	"devper/app/featues/report/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetDailySummary(reportEntity repository.IReport) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetDailySummary{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		_, startDate, endDate, err := getDay(request.Date)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := reportEntity.GetDailySummary(startDate, endDate, request.ShiftId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
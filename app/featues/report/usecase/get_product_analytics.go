@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"devper/app/featues/report/form"
+	"devper/app/featues/report/model"
+	"devper/app/featues/report/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+)
+
+// GetProductAnalytics ranks the best sellers of the period by units, revenue or margin beside the same products
+// in the previous period
+func GetProductAnalytics(reportEntity repository.IReport) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetProductAnalytics{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		period, previousPeriod, err := getPeriod(request.StartDate, request.EndDate)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		products, err := reportEntity.GetProductSales(period.StartDate, period.EndDate, request, nil)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		productIds := make([]primitive.ObjectID, len(products))
+		for index, product := range products {
+			productIds[index] = product.ProductId
+		}
+		previousProducts, err := reportEntity.GetProductSales(previousPeriod.StartDate, previousPeriod.EndDate, request, productIds)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		previous := map[primitive.ObjectID]model.Sales{}
+		for _, product := range previousProducts {
+			previous[product.ProductId] = product.Sales
+		}
+		for index := range products {
+			products[index].Compare(previous[products[index].ProductId])
+		}
+		total, err := getTotal(reportEntity, period, previousPeriod)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, model.ProductAnalytics{
+			Period:         period,
+			PreviousPeriod: previousPeriod,
+			Total:          *total,
+			Products:       products,
+		})
+	}
+}
+
+func getTotal(reportEntity repository.IReport, period model.Period, previousPeriod model.Period) (*model.Comparison, error) {
+	total, err := reportEntity.GetSalesTotal(period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := reportEntity.GetSalesTotal(previousPeriod.StartDate, previousPeriod.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	comparison := model.Comparison{Sales: *total}
+	comparison.Compare(*previous)
+	return &comparison, nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"devper/app/featues/report/form"
+	"devper/app/featues/report/model"
+	"devper/app/featues/report/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// GetTimeAnalytics lays the sales out over every hour of the day and every day of the week, empty slots included
+func GetTimeAnalytics(reportEntity repository.IReport) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetAnalytics{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		period, previousPeriod, err := getPeriod(request.StartDate, request.EndDate)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		hours, err := getTimeSales(reportEntity, period, previousPeriod, "$hour", 0, 23)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		days, err := getTimeSales(reportEntity, period, previousPeriod, "$dayOfWeek", int(time.Sunday)+1, int(time.Saturday)+1)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, model.TimeAnalytics{
+			Period:         period,
+			PreviousPeriod: previousPeriod,
+			Hours:          hours,
+			Days:           days,
+		})
+	}
+}
+
+func getTimeSales(reportEntity repository.IReport, period model.Period, previousPeriod model.Period, slot string, first int, last int) ([]model.TimeSales, error) {
+	current, err := reportEntity.GetTimeSales(period.StartDate, period.EndDate, slot)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := reportEntity.GetTimeSales(previousPeriod.StartDate, previousPeriod.EndDate, slot)
+	if err != nil {
+		return nil, err
+	}
+	slots := map[int]model.TimeSales{}
+	for _, sales := range current {
+		slots[sales.Slot] = sales
+	}
+	previousSlots := map[int]model.Sales{}
+	for _, sales := range previous {
+		previousSlots[sales.Slot] = sales.Sales
+	}
+	items := make([]model.TimeSales, 0, last-first+1)
+	for index := first; index <= last; index++ {
+		sales := slots[index]
+		sales.Slot = index
+		sales.Compare(previousSlots[index])
+		items = append(items, sales)
+	}
+	return items, nil
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/report/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetZReportByDate(reportEntity repository.IReport) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		date := ctx.Param("date")
+		result, err := reportEntity.GetZReportByDate(utils.GetBranchCode(), date)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
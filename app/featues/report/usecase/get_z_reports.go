@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/report/form"
+	"devper/app/featues/report/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetZReports(reportEntity repository.IReport) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetZReports{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := reportEntity.GetZReports(utils.GetBranchCode(), request.StartDate, request.EndDate)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
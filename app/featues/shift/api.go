@@ -0,0 +1,60 @@
+package shift
+
+import (
+	"devper/app/core/constant"
+	repository2 "devper/app/featues/counter/repository"
+	"devper/app/featues/shift/repository"
+	"devper/app/featues/shift/usecase"
+	repository3 "devper/app/featues/user/repository"
+	"devper/db"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplyShiftAPI(
+	app *gin.RouterGroup,
+	transaction db.ITransaction,
+	shiftEntity repository.IShift,
+	counterEntity repository2.ICounter,
+	userEntity repository3.IUser,
+) {
+	shiftRoute := app.Group("shift")
+
+	shiftRoute.POST("",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.OpenShift(transaction, shiftEntity, counterEntity),
+	)
+
+	shiftRoute.GET("/current",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetCurrentShift(shiftEntity),
+	)
+
+	shiftRoute.POST("/cash",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.AddCashEntry(transaction, shiftEntity),
+	)
+
+	shiftRoute.POST("/close",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.CloseShift(transaction, shiftEntity),
+	)
+
+	shiftRoute.GET("",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetShifts(shiftEntity),
+	)
+
+	shiftRoute.GET("/:shiftId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetShiftById(shiftEntity),
+	)
+
+	shiftRoute.POST("/:shiftId/close",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CloseShift(transaction, shiftEntity),
+	)
+}
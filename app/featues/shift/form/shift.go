@@ -0,0 +1,29 @@
+package form
+
+import "time"
+
+type OpenShift struct {
+	OpeningFloat float64 `json:"openingFloat" binding:"min=0"`
+	Note         string  `json:"note"`
+	OpenedBy     string
+}
+
+type CashEntry struct {
+	Type      string  `json:"type" binding:"required,oneof=IN OUT"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	Reason    string  `json:"reason" binding:"required"`
+	CreatedBy string
+}
+
+type CloseShift struct {
+	CountedCash *float64 `json:"countedCash" binding:"required,min=0"`
+	Note        string   `json:"note"`
+	ClosedBy    string
+}
+
+type GetShifts struct {
+	StartDate time.Time `form:"startDate"`
+	EndDate   time.Time `form:"endDate"`
+	Status    string    `form:"status"`
+	OpenedBy  string    `form:"openedBy"`
+}
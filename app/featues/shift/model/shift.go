@@ -0,0 +1,49 @@
+package model
+
+import (
+	"devper/app/core/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Shift struct {
+	Id           primitive.ObjectID `bson:"_id" json:"id"`
+	Number       int64              `bson:"number" json:"number"`
+	Branch       string             `bson:"branch" json:"branch"`
+	Status       string             `bson:"status" json:"status"`
+	OpeningFloat float64            `bson:"openingFloat" json:"openingFloat"`
+	CashIn       float64            `bson:"cashIn" json:"cashIn"`
+	CashOut      float64            `bson:"cashOut" json:"cashOut"`
+	CashSales    float64            `bson:"cashSales" json:"cashSales"`
+	CashRefunds  float64            `bson:"cashRefunds" json:"cashRefunds"`
+	ExpectedCash float64            `bson:"expectedCash" json:"expectedCash"`
+	CountedCash  float64            `bson:"countedCash" json:"countedCash"`
+	Variance     float64            `bson:"variance" json:"variance"`
+	Note         string             `bson:"note" json:"note"`
+	OpenedBy     string             `bson:"openedBy" json:"openedBy"`
+	OpenedDate   time.Time          `bson:"openedDate" json:"openedDate"`
+	ClosedBy     string             `bson:"closedBy" json:"closedBy"`
+	ClosedDate   time.Time          `bson:"closedDate" json:"closedDate"`
+}
+
+// SetExpectedCash works out the cash the drawer should hold from the float, the cash entries and the cash payments
+func (shift *Shift) SetExpectedCash(cashSales float64, cashRefunds float64) {
+	shift.CashSales = cashSales
+	shift.CashRefunds = cashRefunds
+	shift.ExpectedCash = utils.RoundPrice(shift.OpeningFloat + shift.CashIn - shift.CashOut + cashSales - cashRefunds)
+}
+
+type CashEntry struct {
+	Id          primitive.ObjectID `bson:"_id" json:"id"`
+	ShiftId     primitive.ObjectID `bson:"shiftId" json:"shiftId"`
+	Type        string             `bson:"type" json:"type"`
+	Amount      float64            `bson:"amount" json:"amount"`
+	Reason      string             `bson:"reason" json:"reason"`
+	CreatedBy   string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate time.Time          `bson:"createdDate" json:"createdDate"`
+}
+
+type ShiftDetail struct {
+	Shift   `bson:",inline"`
+	Entries []CashEntry `json:"entries"`
+}
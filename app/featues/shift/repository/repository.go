@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/shift/form"
+	"devper/app/featues/shift/model"
+	"devper/db"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+var ErrShiftNotOpen = errors.New("shift is not open")
+
+type shiftEntity struct {
+	shiftRepo     *mongo.Collection
+	cashEntryRepo *mongo.Collection
+	paymentRepo   *mongo.Collection
+}
+
+type IShift interface {
+	CreateIndex() (string, error)
+	OpenShift(ctx context.Context, shift model.Shift) (*model.Shift, error)
+	GetShifts(form form.GetShifts) ([]model.Shift, error)
+	GetShiftById(ctx context.Context, id string) (*model.Shift, error)
+	GetOpenShiftByUserId(userId string) (*model.Shift, error)
+	CreateCashEntry(ctx context.Context, entry model.CashEntry) (*model.Shift, error)
+	GetCashEntriesByShiftId(shiftId string) ([]model.CashEntry, error)
+	GetCashTotals(ctx context.Context, shiftId primitive.ObjectID) (float64, float64, error)
+	CloseShiftById(ctx context.Context, shift model.Shift) (*model.Shift, error)
+}
+
+func NewShiftEntity(resource *db.Resource) IShift {
+	shiftRepo := resource.DB.Collection("shifts")
+	cashEntryRepo := resource.DB.Collection("cash_entries")
+	paymentRepo := resource.DB.Collection("payments")
+	var entity IShift = &shiftEntity{
+		shiftRepo:     shiftRepo,
+		cashEntryRepo: cashEntryRepo,
+		paymentRepo:   paymentRepo,
+	}
+	_, _ = entity.CreateIndex()
+	return entity
+}
+
+// CreateIndex keeps one open shift per cashier
+func (entity *shiftEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mod := mongo.IndexModel{
+		Keys: bson.M{"openedBy": 1},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"status": constant.OPEN}),
+	}
+	ind, err := entity.shiftRepo.Indexes().CreateOne(ctx, mod)
+	if err != nil {
+		return "", err
+	}
+	_, err = entity.cashEntryRepo.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{"shiftId": 1}})
+	if err != nil {
+		return "", err
+	}
+	_, err = entity.paymentRepo.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{"shiftId": 1}})
+	return ind, err
+}
+
+func (entity *shiftEntity) OpenShift(ctx context.Context, shift model.Shift) (*model.Shift, error) {
+	logrus.Info("OpenShift")
+	shift.Id = primitive.NewObjectID()
+	shift.Status = constant.OPEN
+	shift.OpenedDate = time.Now()
+	_, err := entity.shiftRepo.InsertOne(ctx, shift)
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func (entity *shiftEntity) GetShifts(form form.GetShifts) ([]model.Shift, error) {
+	logrus.Info("GetShifts")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	filter := bson.M{}
+	openedDate := bson.M{}
+	if !form.StartDate.IsZero() {
+		openedDate["$gte"] = form.StartDate
+	}
+	if !form.EndDate.IsZero() {
+		openedDate["$lt"] = form.EndDate
+	}
+	if len(openedDate) > 0 {
+		filter["openedDate"] = openedDate
+	}
+	if form.Status != "" {
+		filter["status"] = form.Status
+	}
+	if form.OpenedBy != "" {
+		filter["openedBy"] = form.OpenedBy
+	}
+	var items []model.Shift
+	opts := options.Find().SetSort(bson.D{{Key: "openedDate", Value: -1}})
+	cursor, err := entity.shiftRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.Shift
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.Shift{}
+	}
+	return items, nil
+}
+
+func (entity *shiftEntity) GetShiftById(ctx context.Context, id string) (*model.Shift, error) {
+	logrus.Info("GetShiftById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Shift
+	err := entity.shiftRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// GetOpenShiftByUserId finds the cashier's open shift, fails with mongo.ErrNoDocuments when there is none
+func (entity *shiftEntity) GetOpenShiftByUserId(userId string) (*model.Shift, error) {
+	logrus.Info("GetOpenShiftByUserId")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	var data model.Shift
+	err := entity.shiftRepo.FindOne(ctx, bson.M{"openedBy": userId, "status": constant.OPEN}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// CreateCashEntry records cash paid in or taken out of the drawer and moves the shift totals,
+// fails with ErrShiftNotOpen once the shift is closed
+func (entity *shiftEntity) CreateCashEntry(ctx context.Context, entry model.CashEntry) (*model.Shift, error) {
+	logrus.Info("CreateCashEntry")
+	field := "cashIn"
+	if entry.Type == constant.CashOut {
+		field = "cashOut"
+	}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	update := bson.M{"$inc": bson.M{field: entry.Amount}}
+	var data model.Shift
+	err := entity.shiftRepo.FindOneAndUpdate(ctx, bson.M{"_id": entry.ShiftId, "status": constant.OPEN}, update, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrShiftNotOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Id = primitive.NewObjectID()
+	entry.CreatedDate = time.Now()
+	_, err = entity.cashEntryRepo.InsertOne(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *shiftEntity) GetCashEntriesByShiftId(shiftId string) ([]model.CashEntry, error) {
+	logrus.Info("GetCashEntriesByShiftId")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(shiftId)
+	var items []model.CashEntry
+	opts := options.Find().SetSort(bson.D{{Key: "createdDate", Value: 1}})
+	cursor, err := entity.cashEntryRepo.Find(ctx, bson.M{"shiftId": objId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.CashEntry
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.CashEntry{}
+	}
+	return items, nil
+}
+
+// GetCashTotals sums the cash payments taken and the cash refunds paid out during the shift
+func (entity *shiftEntity) GetCashTotals(ctx context.Context, shiftId primitive.ObjectID) (float64, float64, error) {
+	logrus.Info("GetCashTotals")
+	isStatus := func(statuses ...string) bson.M {
+		return bson.M{"$in": bson.A{"$status", statuses}}
+	}
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"shiftId": shiftId,
+				"type":    constant.CASH,
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":     "",
+				"sales":   bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.ACTIVE, constant.PAID), "$total", 0}}},
+				"refunds": bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(constant.REFUNDED), bson.M{"$multiply": bson.A{"$total", -1}}, 0}}},
+			},
+		},
+	}
+	var result []struct {
+		Sales   float64 `bson:"sales"`
+		Refunds float64 `bson:"refunds"`
+	}
+	cursor, err := entity.paymentRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return 0, 0, err
+	}
+	if len(result) == 0 {
+		return 0, 0, nil
+	}
+	return utils.RoundPrice(result[0].Sales), utils.RoundPrice(result[0].Refunds), nil
+}
+
+// CloseShiftById stores the counted cash and variance, fails with ErrShiftNotOpen when the shift was already closed
+func (entity *shiftEntity) CloseShiftById(ctx context.Context, shift model.Shift) (*model.Shift, error) {
+	logrus.Info("CloseShiftById")
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	update := bson.M{"$set": bson.M{
+		"status":       constant.CLOSED,
+		"cashSales":    shift.CashSales,
+		"cashRefunds":  shift.CashRefunds,
+		"expectedCash": shift.ExpectedCash,
+		"countedCash":  shift.CountedCash,
+		"variance":     shift.Variance,
+		"note":         shift.Note,
+		"closedBy":     shift.ClosedBy,
+		"closedDate":   time.Now(),
+	}}
+	var data model.Shift
+	err := entity.shiftRepo.FindOneAndUpdate(ctx, bson.M{"_id": shift.Id, "status": constant.OPEN}, update, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrShiftNotOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
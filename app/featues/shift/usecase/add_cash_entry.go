@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/shift/form"
+	"devper/app/featues/shift/model"
+	"devper/app/featues/shift/repository"
+	"devper/db"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+// AddCashEntry records cash paid into or taken out of the drawer of the cashier's open shift
+func AddCashEntry(transaction db.ITransaction, shiftEntity repository.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.CashEntry{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CreatedBy = ctx.GetString("UserId")
+		shift, err := shiftEntity.GetOpenShiftByUserId(request.CreatedBy)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": repository.ErrShiftNotOpen.Error()})
+			return
+		}
+
+		entry := model.CashEntry{
+			ShiftId:   shift.Id,
+			Type:      request.Type,
+			Amount:    utils.RoundPrice(request.Amount),
+			Reason:    request.Reason,
+			CreatedBy: request.CreatedBy,
+		}
+		err = transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			updated, err := shiftEntity.CreateCashEntry(sessionCtx, entry)
+			if err != nil {
+				return err
+			}
+			shift = updated
+			return nil
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := getShiftDetail(shiftEntity, shift)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
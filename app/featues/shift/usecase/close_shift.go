@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/shift/form"
+	"devper/app/featues/shift/model"
+	"devper/app/featues/shift/repository"
+	"devper/db"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+// CloseShift compares the counted cash with the cash the drawer should hold and keeps the variance,
+// an admin may close any shift by id, a cashier closes their own
+func CloseShift(transaction db.ITransaction, shiftEntity repository.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.CloseShift{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.ClosedBy = ctx.GetString("UserId")
+		shiftId := ctx.Param("shiftId")
+		if shiftId == "" {
+			shift, err := shiftEntity.GetOpenShiftByUserId(request.ClosedBy)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": repository.ErrShiftNotOpen.Error()})
+				return
+			}
+			shiftId = shift.Id.Hex()
+		}
+
+		var result *model.Shift
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			// re-read in the transaction so cash entries made after the lookup are counted
+			shift, err := shiftEntity.GetShiftById(sessionCtx, shiftId)
+			if err != nil {
+				return err
+			}
+			if shift.Status != constant.OPEN {
+				return repository.ErrShiftNotOpen
+			}
+			cashSales, cashRefunds, err := shiftEntity.GetCashTotals(sessionCtx, shift.Id)
+			if err != nil {
+				return err
+			}
+			shift.SetExpectedCash(cashSales, cashRefunds)
+			shift.CountedCash = utils.RoundPrice(*request.CountedCash)
+			shift.Variance = utils.RoundPrice(shift.CountedCash - shift.ExpectedCash)
+			shift.Note = request.Note
+			shift.ClosedBy = request.ClosedBy
+			closed, err := shiftEntity.CloseShiftById(sessionCtx, *shift)
+			if err != nil {
+				return err
+			}
+			result = closed
+			return nil
+		})
+		if errors.Is(err, repository.ErrShiftNotOpen) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
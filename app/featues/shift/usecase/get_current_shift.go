@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"devper/app/featues/shift/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetCurrentShift(shiftEntity repository.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		shift, err := shiftEntity.GetOpenShiftByUserId(ctx.GetString("UserId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := getShiftDetail(shiftEntity, shift)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/shift/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetShiftById(shiftEntity repository.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c, cancel := utils.InitContext()
+		defer cancel()
+		shift, err := shiftEntity.GetShiftById(c, ctx.Param("shiftId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := getShiftDetail(shiftEntity, shift)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
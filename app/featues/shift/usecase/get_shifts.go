@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"devper/app/featues/shift/form"
+	"devper/app/featues/shift/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetShifts(shiftEntity repository.IShift) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetShifts{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := shiftEntity.GetShifts(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	repository2 "devper/app/featues/counter/repository"
+	"devper/app/featues/shift/form"
+	"devper/app/featues/shift/model"
+	"devper/app/featues/shift/repository"
+	"devper/db"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+// OpenShift starts the cashier's shift with the opening float in the drawer, a cashier has one open shift at a time
+func OpenShift(transaction db.ITransaction, shiftEntity repository.IShift, counterEntity repository2.ICounter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.OpenShift{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.OpenedBy = ctx.GetString("UserId")
+		if _, err := shiftEntity.GetOpenShiftByUserId(request.OpenedBy); err == nil {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "shift is already open"})
+			return
+		}
+
+		branch := utils.GetBranchCode()
+		var result *model.Shift
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			number, err := counterEntity.NextSequence(sessionCtx, "shift-"+branch)
+			if err != nil {
+				return err
+			}
+			shift, err := shiftEntity.OpenShift(sessionCtx, model.Shift{
+				Number:       number,
+				Branch:       branch,
+				OpeningFloat: utils.RoundPrice(request.OpeningFloat),
+				Note:         request.Note,
+				OpenedBy:     request.OpenedBy,
+			})
+			if err != nil {
+				return err
+			}
+			result = shift
+			return nil
+		})
+		if mongo.IsDuplicateKeyError(err) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "shift is already open"})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
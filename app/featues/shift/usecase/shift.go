@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/shift/model"
+	"devper/app/featues/shift/repository"
+)
+
+// getShiftDetail fills in the expected cash of an open shift so far and lists its cash entries
+func getShiftDetail(shiftEntity repository.IShift, shift *model.Shift) (*model.ShiftDetail, error) {
+	if shift.ClosedDate.IsZero() {
+		ctx, cancel := utils.InitContext()
+		defer cancel()
+		cashSales, cashRefunds, err := shiftEntity.GetCashTotals(ctx, shift.Id)
+		if err != nil {
+			return nil, err
+		}
+		shift.SetExpectedCash(cashSales, cashRefunds)
+	}
+	entries, err := shiftEntity.GetCashEntriesByShiftId(shift.Id.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return &model.ShiftDetail{Shift: *shift, Entries: entries}, nil
+}
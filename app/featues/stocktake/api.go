@@ -0,0 +1,57 @@
+package stocktake
+
+import (
+	"devper/app/core/constant"
+	repository3 "devper/app/featues/counter/repository"
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/stocktake/repository"
+	"devper/app/featues/stocktake/usecase"
+	repository4 "devper/app/featues/user/repository"
+	"devper/db"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplyStocktakeAPI(
+	app *gin.RouterGroup,
+	transaction db.ITransaction,
+	stocktakeEntity repository.IStocktake,
+	productEntity repository2.IProduct,
+	counterEntity repository3.ICounter,
+	userEntity repository4.IUser,
+) {
+	stocktakeRoute := app.Group("stocktake")
+
+	stocktakeRoute.POST("",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CreateStocktake(transaction, stocktakeEntity, counterEntity),
+	)
+
+	stocktakeRoute.GET("",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetStocktakes(stocktakeEntity),
+	)
+
+	stocktakeRoute.GET("/:stocktakeId",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetStocktakeById(stocktakeEntity),
+	)
+
+	stocktakeRoute.POST("/:stocktakeId/count",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.CountStock(stocktakeEntity),
+	)
+
+	stocktakeRoute.POST("/:stocktakeId/approve",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.ApproveStocktake(transaction, stocktakeEntity, productEntity),
+	)
+
+	stocktakeRoute.POST("/:stocktakeId/cancel",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CancelStocktake(stocktakeEntity),
+	)
+}
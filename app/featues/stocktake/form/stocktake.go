@@ -0,0 +1,31 @@
+package form
+
+type CreateStocktake struct {
+	Category  string `json:"category"`
+	Note      string `json:"note"`
+	CreatedBy string
+}
+
+type CountStock struct {
+	Items     []CountItem `json:"items" binding:"required,dive"`
+	CountedBy string
+}
+
+// CountItem finds the line by product id or scanned serial number and optionally a lot, add counts on top of
+// what was already counted, one scan at a time
+type CountItem struct {
+	ProductId    string `json:"productId" binding:"required_without=SerialNumber"`
+	SerialNumber string `json:"serialNumber"`
+	LotId        string `json:"lotId"`
+	LotNumber    string `json:"lotNumber"`
+	Quantity     int    `json:"quantity" binding:"min=0"`
+	Add          bool   `json:"add"`
+}
+
+type GetStocktakes struct {
+	Status string `form:"status"`
+}
+
+type GetStocktake struct {
+	Variance bool `form:"variance"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Stocktake struct {
+	Id           primitive.ObjectID `bson:"_id" json:"id"`
+	Number       int64              `bson:"number" json:"number"`
+	Branch       string             `bson:"branch" json:"branch"`
+	Category     string             `bson:"category" json:"category"`
+	Status       string             `bson:"status" json:"status"`
+	Note         string             `bson:"note" json:"note"`
+	CreatedBy    string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate  time.Time          `bson:"createdDate" json:"createdDate"`
+	ApprovedBy   string             `bson:"approvedBy" json:"approvedBy"`
+	ApprovedDate time.Time          `bson:"approvedDate" json:"approvedDate"`
+	UpdatedBy    string             `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate  time.Time          `bson:"updatedDate" json:"updatedDate"`
+}
+
+// StocktakeItem is one product, or one lot of it when LotId is set, with the quantity expected in stock, from when the
+// count started until it is approved against the stock at that time
+type StocktakeItem struct {
+	Id               primitive.ObjectID `bson:"_id" json:"id"`
+	StocktakeId      primitive.ObjectID `bson:"stocktakeId" json:"stocktakeId"`
+	ProductId        primitive.ObjectID `bson:"productId" json:"productId"`
+	LotId            primitive.ObjectID `bson:"lotId,omitempty" json:"lotId,omitempty"`
+	SerialNumber     string             `bson:"serialNumber" json:"serialNumber"`
+	Name             string             `bson:"name" json:"name"`
+	Unit             string             `bson:"unit" json:"unit"`
+	LotNumber        string             `bson:"lotNumber,omitempty" json:"lotNumber,omitempty"`
+	CostPrice        float64            `bson:"costPrice" json:"costPrice"`
+	ExpectedQuantity int                `bson:"expectedQuantity" json:"expectedQuantity"`
+	CountedQuantity  int                `bson:"countedQuantity" json:"countedQuantity"`
+	Counted          bool               `bson:"counted" json:"counted"`
+	Variance         int                `bson:"-" json:"variance"`
+	VarianceValue    float64            `bson:"-" json:"varianceValue"`
+	CountedBy        string             `bson:"countedBy" json:"countedBy"`
+	CountedDate      time.Time          `bson:"countedDate" json:"countedDate"`
+}
+
+func (item *StocktakeItem) SetVariance() {
+	item.Variance = 0
+	if item.Counted {
+		item.Variance = item.CountedQuantity - item.ExpectedQuantity
+	}
+	item.VarianceValue = float64(item.Variance) * item.CostPrice
+}
+
+func (item StocktakeItem) IsLot() bool {
+	return !item.LotId.IsZero()
+}
+
+type StocktakeDetail struct {
+	Stocktake     `bson:",inline"`
+	Lines         int             `json:"lines"`
+	Counted       int             `json:"counted"`
+	Variance      int             `json:"variance"`
+	VarianceValue float64         `json:"varianceValue"`
+	Items         []StocktakeItem `json:"items"`
+}
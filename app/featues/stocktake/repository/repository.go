@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/stocktake/form"
+	"devper/app/featues/stocktake/model"
+	"devper/db"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strings"
+	"time"
+)
+
+var (
+	ErrStocktakeNotOpen = errors.New("stocktake is not open")
+	ErrItemNotFound     = errors.New("item is not in the stocktake")
+)
+
+type stocktakeEntity struct {
+	stocktakeRepo *mongo.Collection
+	itemRepo      *mongo.Collection
+	productRepo   *mongo.Collection
+	lotRepo       *mongo.Collection
+}
+
+type IStocktake interface {
+	CreateIndex() (string, error)
+	CreateStocktake(ctx context.Context, stocktake model.Stocktake) (*model.Stocktake, error)
+	GetStocktakes(form form.GetStocktakes) ([]model.Stocktake, error)
+	GetStocktakeById(id string) (*model.Stocktake, error)
+	GetStocktakeItems(ctx context.Context, stocktakeId string) ([]model.StocktakeItem, error)
+	UpdateExpectedQuantities(ctx context.Context, stocktakeId string) ([]model.StocktakeItem, error)
+	CountItem(stocktakeId string, form form.CountItem, countedBy string) (*model.StocktakeItem, error)
+	UpdateStatusById(ctx context.Context, id string, status string, updatedBy string) (*model.Stocktake, error)
+}
+
+func NewStocktakeEntity(resource *db.Resource) IStocktake {
+	stocktakeRepo := resource.DB.Collection("stocktakes")
+	itemRepo := resource.DB.Collection("stocktake_items")
+	productRepo := resource.DB.Collection("products")
+	lotRepo := resource.DB.Collection("product_lots")
+	var entity IStocktake = &stocktakeEntity{
+		stocktakeRepo: stocktakeRepo,
+		itemRepo:      itemRepo,
+		productRepo:   productRepo,
+		lotRepo:       lotRepo,
+	}
+	_, _ = entity.CreateIndex()
+	return entity
+}
+
+func (entity *stocktakeEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mods := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "stocktakeId", Value: 1}, {Key: "productId", Value: 1}, {Key: "lotId", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "stocktakeId", Value: 1}, {Key: "serialNumber", Value: 1}},
+		},
+	}
+	ind, err := entity.itemRepo.Indexes().CreateMany(ctx, mods)
+	if err != nil {
+		return "", err
+	}
+	return ind[0], nil
+}
+
+// CreateStocktake opens the count with a line for every product in the category, or for all products, and for each
+// of their lots, expecting the quantity in stock when it started
+func (entity *stocktakeEntity) CreateStocktake(ctx context.Context, stocktake model.Stocktake) (*model.Stocktake, error) {
+	logrus.Info("CreateStocktake")
+	stocktake.Id = primitive.NewObjectID()
+	stocktake.Status = constant.OPEN
+	stocktake.CreatedDate = time.Now()
+	stocktake.UpdatedBy = stocktake.CreatedBy
+	stocktake.UpdatedDate = time.Now()
+	_, err := entity.stocktakeRepo.InsertOne(ctx, stocktake)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{}
+	if stocktake.Category != "" {
+		filter["category"] = stocktake.Category
+	}
+	var products []struct {
+		Id           primitive.ObjectID `bson:"_id"`
+		Name         string             `bson:"name"`
+		Unit         string             `bson:"unit"`
+		SerialNumber string             `bson:"serialNumber"`
+		CostPrice    float64            `bson:"costPrice"`
+		Quantity     int                `bson:"quantity"`
+	}
+	cursor, err := entity.productRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, errors.New("no products to count")
+	}
+
+	var items []interface{}
+	productIds := make([]primitive.ObjectID, len(products))
+	lines := map[primitive.ObjectID]model.StocktakeItem{}
+	for index, product := range products {
+		productIds[index] = product.Id
+		item := model.StocktakeItem{
+			Id:               primitive.NewObjectID(),
+			StocktakeId:      stocktake.Id,
+			ProductId:        product.Id,
+			SerialNumber:     product.SerialNumber,
+			Name:             product.Name,
+			Unit:             product.Unit,
+			CostPrice:        product.CostPrice,
+			ExpectedQuantity: product.Quantity,
+		}
+		lines[product.Id] = item
+		items = append(items, item)
+	}
+
+	var lots []struct {
+		Id        primitive.ObjectID `bson:"_id"`
+		ProductId primitive.ObjectID `bson:"productId"`
+		LotNumber string             `bson:"lotNumber"`
+		CostPrice float64            `bson:"costPrice"`
+		Quantity  int                `bson:"quantity"`
+	}
+	cursor, err = entity.lotRepo.Find(ctx, bson.M{"productId": bson.M{"$in": productIds}})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &lots); err != nil {
+		return nil, err
+	}
+	for _, lot := range lots {
+		item := lines[lot.ProductId]
+		item.Id = primitive.NewObjectID()
+		item.LotId = lot.Id
+		item.LotNumber = lot.LotNumber
+		item.CostPrice = lot.CostPrice
+		item.ExpectedQuantity = lot.Quantity
+		items = append(items, item)
+	}
+	_, err = entity.itemRepo.InsertMany(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+func (entity *stocktakeEntity) GetStocktakes(form form.GetStocktakes) ([]model.Stocktake, error) {
+	logrus.Info("GetStocktakes")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	filter := bson.M{}
+	if form.Status != "" {
+		filter["status"] = form.Status
+	}
+	var items []model.Stocktake
+	opts := options.Find().SetSort(bson.D{{Key: "createdDate", Value: -1}})
+	cursor, err := entity.stocktakeRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.Stocktake
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.Stocktake{}
+	}
+	return items, nil
+}
+
+func (entity *stocktakeEntity) GetStocktakeById(id string) (*model.Stocktake, error) {
+	logrus.Info("GetStocktakeById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Stocktake
+	err := entity.stocktakeRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// GetStocktakeItems lists the lines by product, each product line ahead of its lots, with the variance filled in
+func (entity *stocktakeEntity) GetStocktakeItems(ctx context.Context, stocktakeId string) ([]model.StocktakeItem, error) {
+	logrus.Info("GetStocktakeItems")
+	objId, _ := primitive.ObjectIDFromHex(stocktakeId)
+	var items []model.StocktakeItem
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "productId", Value: 1}, {Key: "lotId", Value: 1}})
+	cursor, err := entity.itemRepo.Find(ctx, bson.M{"stocktakeId": objId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.StocktakeItem
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			data.SetVariance()
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.StocktakeItem{}
+	}
+	return items, nil
+}
+
+// UpdateExpectedQuantities re-reads the stock of each counted line, so the variance is against the quantity in stock
+// now rather than when the count started, and keeps it on the line as what the count was approved against
+func (entity *stocktakeEntity) UpdateExpectedQuantities(ctx context.Context, stocktakeId string) ([]model.StocktakeItem, error) {
+	logrus.Info("UpdateExpectedQuantities")
+	items, err := entity.GetStocktakeItems(ctx, stocktakeId)
+	if err != nil {
+		return nil, err
+	}
+	var productIds, lotIds []primitive.ObjectID
+	for _, item := range items {
+		if !item.Counted {
+			continue
+		}
+		if item.IsLot() {
+			lotIds = append(lotIds, item.LotId)
+		} else {
+			productIds = append(productIds, item.ProductId)
+		}
+	}
+	if len(productIds) == 0 && len(lotIds) == 0 {
+		return items, nil
+	}
+
+	quantities := map[primitive.ObjectID]int{}
+	for repo, ids := range map[*mongo.Collection][]primitive.ObjectID{entity.productRepo: productIds, entity.lotRepo: lotIds} {
+		if len(ids) == 0 {
+			continue
+		}
+		var stocks []struct {
+			Id       primitive.ObjectID `bson:"_id"`
+			Quantity int                `bson:"quantity"`
+		}
+		cursor, err := repo.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return nil, err
+		}
+		if err = cursor.All(ctx, &stocks); err != nil {
+			return nil, err
+		}
+		for _, stock := range stocks {
+			quantities[stock.Id] = stock.Quantity
+		}
+	}
+
+	var updates []mongo.WriteModel
+	for index, item := range items {
+		if !item.Counted {
+			continue
+		}
+		id := item.ProductId
+		if item.IsLot() {
+			id = item.LotId
+		}
+		item.ExpectedQuantity = quantities[id]
+		item.SetVariance()
+		items[index] = item
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": item.Id}).
+			SetUpdate(bson.M{"$set": bson.M{"expectedQuantity": item.ExpectedQuantity}}))
+	}
+	_, err = entity.itemRepo.BulkWrite(ctx, updates)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CountItem sets the counted quantity of a line, or adds to it for a scan, fails with ErrStocktakeNotOpen
+// once the count was approved or cancelled
+func (entity *stocktakeEntity) CountItem(stocktakeId string, form form.CountItem, countedBy string) (*model.StocktakeItem, error) {
+	logrus.Info("CountItem")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(stocktakeId)
+	count, err := entity.stocktakeRepo.CountDocuments(ctx, bson.M{"_id": objId, "status": constant.OPEN})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrStocktakeNotOpen
+	}
+
+	filter := bson.M{"stocktakeId": objId}
+	if form.ProductId != "" {
+		productId, _ := primitive.ObjectIDFromHex(form.ProductId)
+		filter["productId"] = productId
+	} else {
+		filter["serialNumber"] = strings.TrimSpace(form.SerialNumber)
+	}
+	if form.LotId != "" {
+		lotId, _ := primitive.ObjectIDFromHex(form.LotId)
+		filter["lotId"] = lotId
+	} else if form.LotNumber != "" {
+		filter["lotNumber"] = form.LotNumber
+	} else {
+		filter["lotId"] = bson.M{"$exists": false}
+	}
+
+	set := bson.M{
+		"counted":     true,
+		"countedBy":   countedBy,
+		"countedDate": time.Now(),
+	}
+	update := bson.M{"$set": set}
+	if form.Add {
+		update["$inc"] = bson.M{"countedQuantity": form.Quantity}
+	} else {
+		set["countedQuantity"] = form.Quantity
+	}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.StocktakeItem
+	err = entity.itemRepo.FindOneAndUpdate(ctx, filter, update, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	data.SetVariance()
+	return &data, nil
+}
+
+// UpdateStatusById closes an open count as approved or cancelled, fails with ErrStocktakeNotOpen when it was already closed
+func (entity *stocktakeEntity) UpdateStatusById(ctx context.Context, id string, status string, updatedBy string) (*model.Stocktake, error) {
+	logrus.Info("UpdateStatusById")
+	objId, _ := primitive.ObjectIDFromHex(id)
+	set := bson.M{
+		"status":      status,
+		"updatedBy":   updatedBy,
+		"updatedDate": time.Now(),
+	}
+	if status == constant.APPROVED {
+		set["approvedBy"] = updatedBy
+		set["approvedDate"] = time.Now()
+	}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.Stocktake
+	err := entity.stocktakeRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId, "status": constant.OPEN}, bson.M{"$set": set}, opts).Decode(&data)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrStocktakeNotOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	model2 "devper/app/featues/product/model"
+	repository2 "devper/app/featues/product/repository"
+	"devper/app/featues/stocktake/model"
+	"devper/app/featues/stocktake/repository"
+	"devper/db"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"strconv"
+)
+
+// ApproveStocktake posts the variances of the counted lines against the stock at approval as stock adjustments, all
+// of them or none
+func ApproveStocktake(transaction db.ITransaction, stocktakeEntity repository.IStocktake, productEntity repository2.IProduct) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		stocktakeId := ctx.Param("stocktakeId")
+		userId := ctx.GetString("UserId")
+
+		var result *model.Stocktake
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			stocktake, err := stocktakeEntity.UpdateStatusById(sessionCtx, stocktakeId, constant.APPROVED, userId)
+			if err != nil {
+				return err
+			}
+			items, err := stocktakeEntity.UpdateExpectedQuantities(sessionCtx, stocktakeId)
+			if err != nil {
+				return err
+			}
+			movement := model2.StockMovement{
+				Type:      constant.StockAdjust,
+				RefType:   constant.RefStocktake,
+				RefId:     stocktake.Id,
+				RefNumber: strconv.FormatInt(stocktake.Number, 10),
+				Note:      stocktake.Note,
+				CreatedBy: userId,
+			}
+			err = postAdjustments(sessionCtx, productEntity, items, movement)
+			if err != nil {
+				return err
+			}
+			result = stocktake
+			return nil
+		})
+		if errors.Is(err, repository.ErrStocktakeNotOpen) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
+
+// postAdjustments corrects the counted lots and the product quantity, from the product line when it was counted
+// or else from the variance of each counted lot
+func postAdjustments(sessionCtx mongo.SessionContext, productEntity repository2.IProduct, items []model.StocktakeItem, movement model2.StockMovement) error {
+	productLines := map[string]model.StocktakeItem{}
+	for _, item := range items {
+		if !item.IsLot() {
+			productLines[item.ProductId.Hex()] = item
+		}
+	}
+	for _, item := range items {
+		if !item.IsLot() || item.Variance == 0 {
+			continue
+		}
+		_, err := productEntity.AdjustLotById(sessionCtx, item.LotId.Hex(), item.Variance)
+		if err != nil {
+			return err
+		}
+		if productLines[item.ProductId.Hex()].Counted {
+			continue
+		}
+		lotMovement := movement
+		lotMovement.LotId = item.LotId
+		lotMovement.LotNumber = item.LotNumber
+		_, err = productEntity.AddQuantityById(sessionCtx, item.ProductId.Hex(), item.Variance, lotMovement)
+		if err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		if item.IsLot() || item.Variance == 0 {
+			continue
+		}
+		_, err := productEntity.AddQuantityById(sessionCtx, item.ProductId.Hex(), item.Variance, movement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	"devper/app/featues/stocktake/repository"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func CancelStocktake(stocktakeEntity repository.IStocktake) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c, cancel := utils.InitContext()
+		defer cancel()
+		result, err := stocktakeEntity.UpdateStatusById(c, ctx.Param("stocktakeId"), constant.CANCELLED, ctx.GetString("UserId"))
+		if errors.Is(err, repository.ErrStocktakeNotOpen) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"devper/app/featues/stocktake/form"
+	"devper/app/featues/stocktake/model"
+	"devper/app/featues/stocktake/repository"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// CountStock records the counted quantities of a batch of lines, the lines counted before a failure keep their count
+func CountStock(stocktakeEntity repository.IStocktake) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		stocktakeId := ctx.Param("stocktakeId")
+		request := form.CountStock{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CountedBy = ctx.GetString("UserId")
+		result := []model.StocktakeItem{}
+		for _, item := range request.Items {
+			counted, err := stocktakeEntity.CountItem(stocktakeId, item, request.CountedBy)
+			if errors.Is(err, repository.ErrStocktakeNotOpen) {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, repository.ErrItemNotFound) {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error(), "productId": item.ProductId, "serialNumber": item.SerialNumber})
+				return
+			}
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			result = append(result, *counted)
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
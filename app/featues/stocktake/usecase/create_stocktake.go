@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	repository2 "devper/app/featues/counter/repository"
+	"devper/app/featues/stocktake/form"
+	"devper/app/featues/stocktake/model"
+	"devper/app/featues/stocktake/repository"
+	"devper/db"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+// CreateStocktake starts a count of one category, or of every product when none is given
+func CreateStocktake(transaction db.ITransaction, stocktakeEntity repository.IStocktake, counterEntity repository2.ICounter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.CreateStocktake{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.CreatedBy = ctx.GetString("UserId")
+
+		branch := utils.GetBranchCode()
+		var result *model.Stocktake
+		err := transaction.WithTransaction(func(sessionCtx mongo.SessionContext) error {
+			number, err := counterEntity.NextSequence(sessionCtx, "stocktake-"+branch)
+			if err != nil {
+				return err
+			}
+			stocktake, err := stocktakeEntity.CreateStocktake(sessionCtx, model.Stocktake{
+				Number:    number,
+				Branch:    branch,
+				Category:  request.Category,
+				Note:      request.Note,
+				CreatedBy: request.CreatedBy,
+			})
+			if err != nil {
+				return err
+			}
+			result = stocktake
+			return nil
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"devper/app/featues/stocktake/form"
+	"devper/app/featues/stocktake/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetStocktakeById(stocktakeEntity repository.IStocktake) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetStocktake{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		stocktake, err := stocktakeEntity.GetStocktakeById(ctx.Param("stocktakeId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := getStocktakeDetail(stocktakeEntity, stocktake, request.Variance)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"devper/app/featues/stocktake/form"
+	"devper/app/featues/stocktake/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetStocktakes(stocktakeEntity repository.IStocktake) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetStocktakes{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := stocktakeEntity.GetStocktakes(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"devper/app/core/utils"
+	"devper/app/featues/stocktake/model"
+	"devper/app/featues/stocktake/repository"
+)
+
+// getStocktakeDetail totals the counted lines of a stocktake, only the lines off by something when variance is set
+func getStocktakeDetail(stocktakeEntity repository.IStocktake, stocktake *model.Stocktake, variance bool) (*model.StocktakeDetail, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	items, err := stocktakeEntity.GetStocktakeItems(ctx, stocktake.Id.Hex())
+	if err != nil {
+		return nil, err
+	}
+	detail := model.StocktakeDetail{
+		Stocktake: *stocktake,
+		Lines:     len(items),
+		Items:     []model.StocktakeItem{},
+	}
+	for _, item := range items {
+		if item.Counted {
+			detail.Counted++
+		}
+		if !item.IsLot() {
+			detail.Variance += item.Variance
+			detail.VarianceValue += item.VarianceValue
+		}
+		if variance && item.Variance == 0 {
+			continue
+		}
+		detail.Items = append(detail.Items, item)
+	}
+	detail.VarianceValue = utils.RoundPrice(detail.VarianceValue)
+	return &detail, nil
+}
@@ -0,0 +1,76 @@
+package supplier
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/supplier/repository"
+	"devper/app/featues/supplier/usecase"
+	repository2 "devper/app/featues/user/repository"
+	"devper/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func ApplySupplierAPI(
+	app *gin.RouterGroup,
+	supplierEntity repository.ISupplier,
+	userEntity repository2.IUser,
+) {
+	supplierRoute := app.Group("supplier")
+
+	supplierRoute.GET("",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetSuppliers(supplierEntity),
+	)
+
+	supplierRoute.POST("",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.CreateSupplier(supplierEntity),
+	)
+
+	supplierRoute.GET("/:supplierId",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetSupplierById(supplierEntity),
+	)
+
+	supplierRoute.PUT("/:supplierId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.UpdateSupplierById(supplierEntity),
+	)
+
+	supplierRoute.DELETE("/:supplierId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.DeleteSupplierById(supplierEntity),
+	)
+
+	supplierRoute.GET("/:supplierId/product",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetSupplierProducts(supplierEntity),
+	)
+
+	supplierRoute.PUT("/:supplierId/product/:productId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.SetProductSupplier(supplierEntity),
+	)
+
+	supplierRoute.DELETE("/:supplierId/product/:productId",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.RemoveProductSupplier(supplierEntity),
+	)
+
+	supplierRoute.GET("/:supplierId/lot",
+		middlewares.RequireAuthenticated(userEntity),
+		middlewares.RequireAuthorization(constant.ADMIN),
+		usecase.GetSupplierLots(supplierEntity),
+	)
+
+	productRoute := app.Group("product")
+
+	productRoute.GET("/:productId/supplier",
+		middlewares.RequireAuthenticated(userEntity),
+		usecase.GetProductSuppliers(supplierEntity),
+	)
+}
@@ -0,0 +1,39 @@
+package form
+
+type Supplier struct {
+	Name            string    `json:"name" binding:"required"`
+	TaxId           string    `json:"taxId"`
+	Phone           string    `json:"phone"`
+	Email           string    `json:"email" binding:"omitempty,email"`
+	Address         string    `json:"address"`
+	Contacts        []Contact `json:"contacts" binding:"omitempty,dive"`
+	PaymentTermDays int       `json:"paymentTermDays" binding:"min=0"`
+	LeadTimeDays    int       `json:"leadTimeDays" binding:"min=0"`
+	Note            string    `json:"note"`
+	UpdatedBy       string
+}
+
+type Contact struct {
+	Name     string `json:"name" binding:"required"`
+	Position string `json:"position"`
+	Phone    string `json:"phone"`
+	Email    string `json:"email" binding:"omitempty,email"`
+}
+
+type GetSuppliers struct {
+	Search string `form:"search"`
+	Status string `form:"status"`
+	Page   int64  `form:"page"`
+	Size   int64  `form:"size"`
+}
+
+type ProductSupplier struct {
+	SupplierSku string `json:"supplierSku"`
+	Preferred   bool   `json:"preferred"`
+	UpdatedBy   string
+}
+
+type GetSupplierLots struct {
+	ProductId string `form:"productId"`
+	LotNumber string `form:"lotNumber"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// ProductSupplier links a product to a supplier it can be bought from, at most one per product is preferred
+type ProductSupplier struct {
+	Id                primitive.ObjectID `bson:"_id" json:"id"`
+	ProductId         primitive.ObjectID `bson:"productId" json:"productId"`
+	SupplierId        primitive.ObjectID `bson:"supplierId" json:"supplierId"`
+	SupplierName      string             `bson:"supplierName" json:"supplierName"`
+	SupplierSku       string             `bson:"supplierSku" json:"supplierSku"`
+	Preferred         bool               `bson:"preferred" json:"preferred"`
+	LastPurchasePrice float64            `bson:"lastPurchasePrice" json:"lastPurchasePrice"`
+	LastPurchaseDate  time.Time          `bson:"lastPurchaseDate" json:"lastPurchaseDate"`
+	UpdatedBy         string             `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate       time.Time          `bson:"updatedDate" json:"updatedDate"`
+}
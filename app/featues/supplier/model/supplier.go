@@ -0,0 +1,31 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Supplier struct {
+	Id              primitive.ObjectID `bson:"_id" json:"id"`
+	Name            string             `bson:"name" json:"name"`
+	TaxId           string             `bson:"taxId" json:"taxId"`
+	Phone           string             `bson:"phone" json:"phone"`
+	Email           string             `bson:"email" json:"email"`
+	Address         string             `bson:"address" json:"address"`
+	Contacts        []Contact          `bson:"contacts" json:"contacts"`
+	PaymentTermDays int                `bson:"paymentTermDays" json:"paymentTermDays"`
+	LeadTimeDays    int                `bson:"leadTimeDays" json:"leadTimeDays"`
+	Note            string             `bson:"note" json:"note"`
+	Status          string             `bson:"status" json:"status"`
+	CreatedBy       string             `bson:"createdBy" json:"createdBy"`
+	CreatedDate     time.Time          `bson:"createdDate" json:"createdDate"`
+	UpdatedBy       string             `bson:"updatedBy" json:"updatedBy"`
+	UpdatedDate     time.Time          `bson:"updatedDate" json:"updatedDate"`
+}
+
+type Contact struct {
+	Name     string `bson:"name" json:"name"`
+	Position string `bson:"position" json:"position"`
+	Phone    string `bson:"phone" json:"phone"`
+	Email    string `bson:"email" json:"email"`
+}
@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"context"
+	"devper/app/core/constant"
+	"devper/app/core/utils"
+	model2 "devper/app/featues/product/model"
+	"devper/app/featues/supplier/form"
+	"devper/app/featues/supplier/model"
+	"devper/db"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrProductNotFound = errors.New("product is not found")
+
+type supplierEntity struct {
+	supplierRepo        *mongo.Collection
+	productSupplierRepo *mongo.Collection
+	productRepo         *mongo.Collection
+	lotRepo             *mongo.Collection
+}
+
+type ISupplier interface {
+	CreateIndex() (string, error)
+	GetSuppliers(form form.GetSuppliers) ([]model.Supplier, int64, error)
+	CreateSupplier(form form.Supplier) (*model.Supplier, error)
+	GetSupplierById(id string) (*model.Supplier, error)
+	UpdateSupplierById(id string, form form.Supplier) (*model.Supplier, error)
+	UpdateStatusById(id string, status string, updatedBy string) (*model.Supplier, error)
+
+	GetProductSuppliers(productId string) ([]model.ProductSupplier, error)
+	GetSupplierProducts(supplierId string) ([]model.ProductSupplier, error)
+	SetProductSupplier(supplierId string, productId string, form form.ProductSupplier) (*model.ProductSupplier, error)
+	RemoveProductSupplier(supplierId string, productId string) (*model.ProductSupplier, error)
+	UpdateLastPurchase(ctx context.Context, supplierId primitive.ObjectID, productId primitive.ObjectID, price float64, updatedBy string) error
+	GetLotsBySupplierId(supplierId string, form form.GetSupplierLots) ([]model2.ProductLot, error)
+}
+
+func NewSupplierEntity(resource *db.Resource) ISupplier {
+	supplierRepo := resource.DB.Collection("suppliers")
+	productSupplierRepo := resource.DB.Collection("product_suppliers")
+	productRepo := resource.DB.Collection("products")
+	lotRepo := resource.DB.Collection("product_lots")
+	var entity ISupplier = &supplierEntity{
+		supplierRepo:        supplierRepo,
+		productSupplierRepo: productSupplierRepo,
+		productRepo:         productRepo,
+		lotRepo:             lotRepo,
+	}
+	_, _ = entity.CreateIndex()
+	return entity
+}
+
+func (entity *supplierEntity) CreateIndex() (string, error) {
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	mod := mongo.IndexModel{
+		Keys: bson.M{
+			"name": 1,
+		},
+	}
+	ind, err := entity.supplierRepo.Indexes().CreateOne(ctx, mod)
+	if err != nil {
+		return "", err
+	}
+	_, err = entity.productSupplierRepo.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "productId", Value: 1}, {Key: "supplierId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	_, err = entity.lotRepo.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"supplierId": 1},
+	})
+	return ind, err
+}
+
+// GetSuppliers pages through the suppliers by name, a search matches part of the name, phone or tax id
+func (entity *supplierEntity) GetSuppliers(form form.GetSuppliers) ([]model.Supplier, int64, error) {
+	logrus.Info("GetSuppliers")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+
+	filter := bson.M{}
+	search := strings.TrimSpace(form.Search)
+	if search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+		filter["$or"] = bson.A{bson.M{"name": pattern}, bson.M{"phone": pattern}, bson.M{"taxId": pattern}}
+	}
+	if form.Status != "" {
+		filter["status"] = form.Status
+	}
+
+	count, err := entity.supplierRepo.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	page := form.Page
+	if page < 1 {
+		page = 1
+	}
+	size := form.Size
+	if size < 1 || size > 100 {
+		size = 20
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip((page - 1) * size).
+		SetLimit(size)
+
+	var items []model.Supplier
+	cursor, err := entity.supplierRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	for cursor.Next(ctx) {
+		var data model.Supplier
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.Supplier{}
+	}
+	return items, count, nil
+}
+
+func setSupplier(data *model.Supplier, form form.Supplier) {
+	data.Name = strings.TrimSpace(form.Name)
+	data.TaxId = strings.TrimSpace(form.TaxId)
+	data.Phone = form.Phone
+	data.Email = form.Email
+	data.Address = form.Address
+	data.Contacts = []model.Contact{}
+	for _, contact := range form.Contacts {
+		data.Contacts = append(data.Contacts, model.Contact{
+			Name:     contact.Name,
+			Position: contact.Position,
+			Phone:    contact.Phone,
+			Email:    contact.Email,
+		})
+	}
+	data.PaymentTermDays = form.PaymentTermDays
+	data.LeadTimeDays = form.LeadTimeDays
+	data.Note = form.Note
+	data.UpdatedBy = form.UpdatedBy
+	data.UpdatedDate = time.Now()
+}
+
+func (entity *supplierEntity) CreateSupplier(form form.Supplier) (*model.Supplier, error) {
+	logrus.Info("CreateSupplier")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	data := model.Supplier{
+		Id:          primitive.NewObjectID(),
+		Status:      constant.ACTIVE,
+		CreatedBy:   form.UpdatedBy,
+		CreatedDate: time.Now(),
+	}
+	setSupplier(&data, form)
+	_, err := entity.supplierRepo.InsertOne(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *supplierEntity) GetSupplierById(id string) (*model.Supplier, error) {
+	logrus.Info("GetSupplierById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Supplier
+	err := entity.supplierRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *supplierEntity) UpdateSupplierById(id string, form form.Supplier) (*model.Supplier, error) {
+	logrus.Info("UpdateSupplierById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	var data model.Supplier
+	err := entity.supplierRepo.FindOne(ctx, bson.M{"_id": objId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	setSupplier(&data, form)
+
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	update := bson.M{"$set": bson.M{
+		"name":            data.Name,
+		"taxId":           data.TaxId,
+		"phone":           data.Phone,
+		"email":           data.Email,
+		"address":         data.Address,
+		"contacts":        data.Contacts,
+		"paymentTermDays": data.PaymentTermDays,
+		"leadTimeDays":    data.LeadTimeDays,
+		"note":            data.Note,
+		"updatedBy":       data.UpdatedBy,
+		"updatedDate":     data.UpdatedDate,
+	}}
+	err = entity.supplierRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	_, err = entity.productSupplierRepo.UpdateMany(ctx, bson.M{"supplierId": objId}, bson.M{"$set": bson.M{"supplierName": data.Name}})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// UpdateStatusById deactivates a supplier instead of deleting it, purchase orders keep pointing at it
+func (entity *supplierEntity) UpdateStatusById(id string, status string, updatedBy string) (*model.Supplier, error) {
+	logrus.Info("UpdateStatusById")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(id)
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"updatedBy":   updatedBy,
+		"updatedDate": time.Now(),
+	}}
+	isReturnNewDoc := options.After
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+	}
+	var data model.Supplier
+	err := entity.supplierRepo.FindOneAndUpdate(ctx, bson.M{"_id": objId}, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *supplierEntity) findProductSuppliers(ctx context.Context, filter bson.M) ([]model.ProductSupplier, error) {
+	var items []model.ProductSupplier
+	opts := options.Find().SetSort(bson.D{{Key: "preferred", Value: -1}, {Key: "supplierName", Value: 1}})
+	cursor, err := entity.productSupplierRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model.ProductSupplier
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model.ProductSupplier{}
+	}
+	return items, nil
+}
+
+// GetProductSuppliers lists who a product can be bought from, the preferred supplier first
+func (entity *supplierEntity) GetProductSuppliers(productId string) ([]model.ProductSupplier, error) {
+	logrus.Info("GetProductSuppliers")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(productId)
+	return entity.findProductSuppliers(ctx, bson.M{"productId": objId})
+}
+
+func (entity *supplierEntity) GetSupplierProducts(supplierId string) ([]model.ProductSupplier, error) {
+	logrus.Info("GetSupplierProducts")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(supplierId)
+	return entity.findProductSuppliers(ctx, bson.M{"supplierId": objId})
+}
+
+// SetProductSupplier links the product to the supplier or updates the link, marking it preferred takes the mark
+// from the product's other suppliers
+func (entity *supplierEntity) SetProductSupplier(supplierId string, productId string, form form.ProductSupplier) (*model.ProductSupplier, error) {
+	logrus.Info("SetProductSupplier")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	supplier, err := entity.GetSupplierById(supplierId)
+	if err != nil {
+		return nil, err
+	}
+	productObjId, _ := primitive.ObjectIDFromHex(productId)
+	count, err := entity.productRepo.CountDocuments(ctx, bson.M{"_id": productObjId})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	if form.Preferred {
+		filter := bson.M{"productId": productObjId, "supplierId": bson.M{"$ne": supplier.Id}}
+		_, err = entity.productSupplierRepo.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"preferred": false}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"supplierName": supplier.Name,
+			"supplierSku":  strings.TrimSpace(form.SupplierSku),
+			"preferred":    form.Preferred,
+			"updatedBy":    form.UpdatedBy,
+			"updatedDate":  time.Now(),
+		},
+	}
+	isReturnNewDoc := options.After
+	isUpsert := true
+	opts := &options.FindOneAndUpdateOptions{
+		ReturnDocument: &isReturnNewDoc,
+		Upsert:         &isUpsert,
+	}
+	var data model.ProductSupplier
+	filter := bson.M{"productId": productObjId, "supplierId": supplier.Id}
+	err = entity.productSupplierRepo.FindOneAndUpdate(ctx, filter, update, opts).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (entity *supplierEntity) RemoveProductSupplier(supplierId string, productId string) (*model.ProductSupplier, error) {
+	logrus.Info("RemoveProductSupplier")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	supplierObjId, _ := primitive.ObjectIDFromHex(supplierId)
+	productObjId, _ := primitive.ObjectIDFromHex(productId)
+	var data model.ProductSupplier
+	err := entity.productSupplierRepo.FindOneAndDelete(ctx, bson.M{"productId": productObjId, "supplierId": supplierObjId}).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// UpdateLastPurchase keeps the price a product was last received at from the supplier, linking the two when
+// the product was bought there for the first time
+func (entity *supplierEntity) UpdateLastPurchase(ctx context.Context, supplierId primitive.ObjectID, productId primitive.ObjectID, price float64, updatedBy string) error {
+	logrus.Info("UpdateLastPurchase")
+	var supplier model.Supplier
+	err := entity.supplierRepo.FindOne(ctx, bson.M{"_id": supplierId}).Decode(&supplier)
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"supplierName":      supplier.Name,
+			"lastPurchasePrice": price,
+			"lastPurchaseDate":  time.Now(),
+			"updatedBy":         updatedBy,
+			"updatedDate":       time.Now(),
+		},
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err = entity.productSupplierRepo.UpdateOne(ctx, bson.M{"productId": productId, "supplierId": supplierId}, update, opts)
+	return err
+}
+
+// GetLotsBySupplierId traces the lots received from a supplier, newest first, to find where a batch went
+func (entity *supplierEntity) GetLotsBySupplierId(supplierId string, form form.GetSupplierLots) ([]model2.ProductLot, error) {
+	logrus.Info("GetLotsBySupplierId")
+	ctx, cancel := utils.InitContext()
+	defer cancel()
+	objId, _ := primitive.ObjectIDFromHex(supplierId)
+	filter := bson.M{"supplierId": objId}
+	if form.ProductId != "" {
+		productId, _ := primitive.ObjectIDFromHex(form.ProductId)
+		filter["productId"] = productId
+	}
+	if form.LotNumber != "" {
+		filter["lotNumber"] = strings.TrimSpace(form.LotNumber)
+	}
+	var items []model2.ProductLot
+	opts := options.Find().SetSort(bson.D{{Key: "createdDate", Value: -1}})
+	cursor, err := entity.lotRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var data model2.ProductLot
+		err = cursor.Decode(&data)
+		if err != nil {
+			logrus.Error(err)
+		} else {
+			items = append(items, data)
+		}
+	}
+	if items == nil {
+		items = []model2.ProductLot{}
+	}
+	return items, nil
+}
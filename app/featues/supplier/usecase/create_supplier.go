@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"devper/app/featues/supplier/form"
+	"devper/app/featues/supplier/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func CreateSupplier(entity repository.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.Supplier{}
+		if err := ctx.ShouldBind(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		request.UpdatedBy = ctx.GetString("UserId")
+		result, err := entity.CreateSupplier(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"devper/app/core/constant"
+	"devper/app/featues/supplier/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func DeleteSupplierById(entity repository.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierId := ctx.Param("supplierId")
+		result, err := entity.UpdateStatusById(supplierId, constant.INACTIVE, ctx.GetString("UserId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"devper/app/featues/supplier/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetProductSuppliers(entity repository.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId := ctx.Param("productId")
+		result, err := entity.GetProductSuppliers(productId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
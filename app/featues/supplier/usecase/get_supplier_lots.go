@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"devper/app/featues/supplier/form"
+	"devper/app/featues/supplier/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetSupplierLots(entity repository.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierId := ctx.Param("supplierId")
+		request := form.GetSupplierLots{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := entity.GetLotsBySupplierId(supplierId, request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"devper/app/featues/supplier/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetSupplierProducts(entity repository.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierId := ctx.Param("supplierId")
+		result, err := entity.GetSupplierProducts(supplierId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"devper/app/featues/supplier/form"
+	"devper/app/featues/supplier/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func GetSuppliers(entity repository.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request := form.GetSuppliers{}
+		if err := ctx.ShouldBindQuery(&request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, total, err := entity.GetSuppliers(request)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
+		ctx.JSON(http.StatusOK, result)
+	}
+}
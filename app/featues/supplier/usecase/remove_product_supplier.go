@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"devper/app/featues/supplier/repository"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func RemoveProductSupplier(entity repository.ISupplier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierId := ctx.Param("supplierId")
+		productId := ctx.Param("productId")
+		result, err := entity.RemoveProductSupplier(supplierId, productId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
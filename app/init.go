@@ -3,12 +3,29 @@ package app
 import (
 	"devper/app/featues/category"
 	"devper/app/featues/category/repository"
+	repository6 "devper/app/featues/counter/repository"
+	"devper/app/featues/customer"
+	repository8 "devper/app/featues/customer/repository"
+	"devper/app/featues/loyalty"
+	repository9 "devper/app/featues/loyalty/repository"
 	"devper/app/featues/notification"
 	repository2 "devper/app/featues/notification/repository"
 	"devper/app/featues/order"
 	repository3 "devper/app/featues/order/repository"
 	"devper/app/featues/product"
 	repository4 "devper/app/featues/product/repository"
+	"devper/app/featues/promotion"
+	repository7 "devper/app/featues/promotion/repository"
+	"devper/app/featues/purchase"
+	repository14 "devper/app/featues/purchase/repository"
+	"devper/app/featues/report"
+	repository10 "devper/app/featues/report/repository"
+	"devper/app/featues/shift"
+	repository11 "devper/app/featues/shift/repository"
+	"devper/app/featues/stocktake"
+	repository12 "devper/app/featues/stocktake/repository"
+	"devper/app/featues/supplier"
+	repository13 "devper/app/featues/supplier/repository"
 	"devper/app/featues/user"
 	repository5 "devper/app/featues/user/repository"
 	"devper/db"
@@ -46,16 +63,35 @@ func (app Routes) StartGin() {
 	orderEntity := repository3.NewOrderEntity(resource)
 	notificationEntity := repository2.NewNotificationEntity(resource)
 	categoryEntity := repository.NewCategoryEntity(resource)
+	counterEntity := repository6.NewCounterEntity(resource)
+	promotionEntity := repository7.NewPromotionEntity(resource)
+	customerEntity := repository8.NewCustomerEntity(resource)
+	pointEntity := repository9.NewPointEntity(resource)
+	reportEntity := repository10.NewReportEntity(resource)
+	shiftEntity := repository11.NewShiftEntity(resource)
+	stocktakeEntity := repository12.NewStocktakeEntity(resource)
+	supplierEntity := repository13.NewSupplierEntity(resource)
+	purchaseEntity := repository14.NewPurchaseEntity(resource)
 
 	user.ApplyAuthAPI(publicRoute, userEntity)
 	user.ApplyUserAPI(publicRoute, userEntity)
 	notification.ApplyNotificationAPI(publicRoute, notificationEntity, userEntity)
-	product.ApplyProductAPI(publicRoute, productEntity, userEntity)
-	order.ApplyOrderAPI(publicRoute, orderEntity, productEntity, userEntity)
+	product.ApplyProductAPI(publicRoute, resource, productEntity, userEntity)
+	order.ApplyOrderAPI(publicRoute, resource, orderEntity, productEntity, userEntity, counterEntity, promotionEntity, customerEntity, pointEntity, shiftEntity)
 	category.ApplyCategoryAPI(publicRoute, categoryEntity, userEntity)
+	promotion.ApplyPromotionAPI(publicRoute, promotionEntity, userEntity)
+	customer.ApplyCustomerAPI(publicRoute, customerEntity, orderEntity, userEntity)
+	loyalty.ApplyLoyaltyAPI(publicRoute, pointEntity, customerEntity, userEntity)
+	report.ApplyReportAPI(publicRoute, resource, reportEntity, counterEntity, userEntity)
+	shift.ApplyShiftAPI(publicRoute, resource, shiftEntity, counterEntity, userEntity)
+	stocktake.ApplyStocktakeAPI(publicRoute, resource, stocktakeEntity, productEntity, counterEntity, userEntity)
+	supplier.ApplySupplierAPI(publicRoute, supplierEntity, userEntity)
+	purchase.ApplyPurchaseAPI(publicRoute, resource, purchaseEntity, productEntity, supplierEntity, counterEntity, userEntity)
 
 	r.NoRoute(middlewares.NoRoute())
 
+	product.StartExpiryDigest(productEntity)
+
 	err = r.Run(":" + os.Getenv("PORT"))
 	if err != nil {
 		logrus.Error(err)
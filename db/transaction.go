@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+type ITransaction interface {
+	WithTransaction(fn func(ctx mongo.SessionContext) error) error
+}
+
+// WithTransaction runs fn inside a multi-document transaction, requires a replica set
+func (r *Resource) WithTransaction(fn func(ctx mongo.SessionContext) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
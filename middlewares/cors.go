@@ -16,7 +16,7 @@ func NewCors(allowedOrigins []string) gin.HandlerFunc {
 			"Accept-Encoding", "Accept-Language", "Accept",
 			"X-CSRF-Token", "Authorization", "X-Requested-With", "X-Access-Token",
 		},
-		ExposedHeaders:   []string{"Content-Length"},
+		ExposedHeaders:   []string{"Content-Length", "X-Total-Count"},
 		AllowCredentials: true,
 	})
 }